@@ -0,0 +1,105 @@
+package debugger
+
+import (
+	"context"
+	"github.com/onflow/flow-go/engine/common/rpc/convert"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/pkg/errors"
+)
+
+var _ TransactionResolver = &AccessTransactions{}
+var _ TransactionIdentifier = &AccessTransactions{}
+
+// AccessTransactions implements transaction resolver that fetches existing transaction
+// from the Flow network using the Flow Access API, for networks where no archive node is available.
+type AccessTransactions struct {
+	Client access.AccessAPIClient
+	Chain  flow.Chain
+	ID     flow.Identifier
+}
+
+func (a *AccessTransactions) TransactionBody() (*flow.TransactionBody, error) {
+	response, err := a.Client.GetTransaction(
+		context.Background(),
+		&access.GetTransactionRequest{
+			Id: a.ID[:],
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get transaction from the access node")
+	}
+
+	txBody, err := convert.MessageToTransaction(response.Transaction, a.Chain)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed decoding transaction")
+	}
+
+	return &txBody, nil
+}
+
+func (a *AccessTransactions) TransactionID() (flow.Identifier, error) {
+	return a.ID, nil
+}
+
+func (a *AccessTransactions) BlockHeight() (uint64, error) {
+	response, err := a.Client.GetTransactionResult(
+		context.Background(),
+		&access.GetTransactionRequest{
+			Id: a.ID[:],
+		},
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get transaction result from the access node")
+	}
+
+	// older access nodes do not return the height with the result
+	if response.BlockHeight != 0 {
+		return response.BlockHeight, nil
+	}
+
+	header, err := a.Client.GetBlockHeaderByID(
+		context.Background(),
+		&access.GetBlockHeaderByIDRequest{
+			Id: response.BlockId,
+		},
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get transaction block from the access node")
+	}
+
+	return header.Block.Height, nil
+}
+
+// TransactionResult returns the result of the transaction as it was executed on the network.
+func (a *AccessTransactions) TransactionResult() (*access.TransactionResultResponse, error) {
+	response, err := a.Client.GetTransactionResult(
+		context.Background(),
+		&access.GetTransactionRequest{
+			Id: a.ID[:],
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get transaction result from the access node")
+	}
+	return response, nil
+}
+
+// GetBlockHeader returns the header of the sealed block at the given height from the access node.
+func GetBlockHeader(client access.AccessAPIClient, height uint64) (*flow.Header, error) {
+	response, err := client.GetBlockHeaderByHeight(
+		context.Background(),
+		&access.GetBlockHeaderByHeightRequest{
+			Height: height,
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get block header from the access node")
+	}
+
+	header, err := convert.MessageToBlockHeader(response.Block)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed decoding block header")
+	}
+	return header, nil
+}
@@ -0,0 +1,94 @@
+package debugger
+
+import (
+	"fmt"
+	"github.com/onflow/flow-dps/api/dps"
+	"google.golang.org/grpc"
+	"sync"
+)
+
+// ArchivePool keeps one connection per archive host, so that many debugger runs
+// can share the same connections instead of dialing for each run.
+// Connections are dialed lazily and stay open until the pool is closed.
+type ArchivePool struct {
+	hosts  []string
+	config ConnectionConfig
+
+	mu      sync.Mutex
+	conns   map[string]*grpc.ClientConn
+	clients []dps.APIClient
+	closed  bool
+}
+
+func NewArchivePool(hosts []string, config ConnectionConfig) (*ArchivePool, error) {
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("at least one archive host is required")
+	}
+	for i, host := range hosts {
+		if host == "" {
+			return nil, fmt.Errorf("archive host %d is empty", i)
+		}
+	}
+	return &ArchivePool{
+		hosts:  hosts,
+		config: config,
+		conns:  make(map[string]*grpc.ClientConn),
+	}, nil
+}
+
+// Clients returns an archive client for every host of the pool, in the order the hosts were given.
+func (p *ArchivePool) Clients() ([]dps.APIClient, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, fmt.Errorf("archive pool is closed")
+	}
+	if p.clients != nil {
+		return p.clients, nil
+	}
+
+	clients := make([]dps.APIClient, 0, len(p.hosts))
+	for _, host := range p.hosts {
+		conn, ok := p.conns[host]
+		if !ok {
+			var err error
+			conn, err = Dial(host, p.config)
+			if err != nil {
+				return nil, fmt.Errorf("could not connect to archive host %s: %w", host, err)
+			}
+			p.conns[host] = conn
+		}
+		clients = append(clients, dps.NewAPIClient(conn))
+	}
+	p.clients = clients
+	return clients, nil
+}
+
+// Client returns the client of the first archive host.
+func (p *ArchivePool) Client() (dps.APIClient, error) {
+	clients, err := p.Clients()
+	if err != nil {
+		return nil, err
+	}
+	return clients[0], nil
+}
+
+// Close closes all the connections of the pool.
+func (p *ArchivePool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	p.clients = nil
+
+	var firstErr error
+	for host, conn := range p.conns {
+		err := conn.Close()
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("could not close connection to %s: %w", host, err)
+		}
+		delete(p.conns, host)
+	}
+	return firstErr
+}
@@ -0,0 +1,27 @@
+package debugger
+
+import "testing"
+
+func TestNewArchivePool(t *testing.T) {
+	tests := []struct {
+		name  string
+		hosts []string
+		err   bool
+	}{
+		{name: "one host", hosts: []string{"archive:9000"}},
+		{name: "many hosts", hosts: []string{"a:9000", "b:9000"}},
+		{name: "no hosts", err: true},
+		{name: "empty host", hosts: []string{"a:9000", ""}, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool, err := NewArchivePool(tt.hosts, ConnectionConfig{})
+			if (err != nil) != tt.err {
+				t.Fatalf("error is %v", err)
+			}
+			if pool != nil {
+				_ = pool.Close()
+			}
+		})
+	}
+}
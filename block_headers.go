@@ -0,0 +1,85 @@
+package debugger
+
+import (
+	"context"
+	"github.com/onflow/flow-dps/api/dps"
+	"github.com/onflow/flow-dps/codec/zbor"
+	"github.com/onflow/flow-go/fvm/environment"
+	fvmErrors "github.com/onflow/flow-go/fvm/errors"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/pkg/errors"
+	"strconv"
+)
+
+// BlockHeaders looks up the headers of finalized blocks by height.
+type BlockHeaders interface {
+	BlockHeader(height uint64) (*flow.Header, error)
+}
+
+var _ BlockHeaders = &NetworkBlockHeaders{}
+
+// NetworkBlockHeaders implements block headers lookup using the archive node client.
+type NetworkBlockHeaders struct {
+	Client dps.APIClient
+}
+
+func (n *NetworkBlockHeaders) BlockHeader(height uint64) (*flow.Header, error) {
+	response, err := n.Client.GetHeader(
+		context.Background(),
+		&dps.GetHeaderRequest{
+			Height: height,
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get block header from the network")
+	}
+
+	codec := zbor.NewCodec()
+	var header flow.Header
+	err = codec.Unmarshal(response.Data, &header)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed decoding block header")
+	}
+
+	return &header, nil
+}
+
+var _ BlockHeaders = &AccessBlockHeaders{}
+
+// AccessBlockHeaders implements block headers lookup using the Flow Access API.
+type AccessBlockHeaders struct {
+	Client access.AccessAPIClient
+}
+
+func (a *AccessBlockHeaders) BlockHeader(height uint64) (*flow.Header, error) {
+	return GetBlockHeader(a.Client, height)
+}
+
+var _ environment.Blocks = &BlockFinder{}
+
+// BlockFinder implements the FVM block lookup (used by getBlock(at:)) on top of a block headers source.
+// Only finalized blocks are available, so blocks are looked up by height directly.
+type BlockFinder struct {
+	Headers BlockHeaders
+}
+
+func (b *BlockFinder) ByHeightFrom(height uint64, header *flow.Header) (*flow.Header, error) {
+	if header != nil {
+		if header.Height == height {
+			return header, nil
+		}
+		if height > header.Height {
+			err := fvmErrors.NewValueErrorf(
+				strconv.FormatUint(height, 10),
+				"requested height (%d) is not in the range(%d, %d)", height, 0, header.Height)
+			return nil, errors.Wrap(err, "cannot retrieve block parent")
+		}
+	}
+
+	found, err := b.Headers.BlockHeader(height)
+	if err != nil {
+		return nil, fvmErrors.NewBlockFinderFailure(err)
+	}
+	return found, nil
+}
@@ -0,0 +1,164 @@
+package debugger
+
+import (
+	"context"
+	"fmt"
+	"github.com/onflow/flow-dps/api/dps"
+	"github.com/onflow/flow-dps/codec/zbor"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/pkg/errors"
+)
+
+// ListBlockTransactions returns the IDs of the transactions of the block at the given height,
+// in the order they are indexed by the archive node.
+func ListBlockTransactions(client dps.APIClient, height uint64) ([]flow.Identifier, error) {
+	response, err := client.ListTransactionsForHeight(
+		context.Background(),
+		&dps.ListTransactionsForHeightRequest{
+			Height: height,
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list block transactions from the network")
+	}
+
+	txIDs := make([]flow.Identifier, 0, len(response.TransactionIDs))
+	for _, txID := range response.TransactionIDs {
+		txIDs = append(txIDs, flow.HashToID(txID))
+	}
+	return txIDs, nil
+}
+
+// ListCollectionTransactions returns the IDs of the transactions of the collection, in execution order.
+func ListCollectionTransactions(client dps.APIClient, collectionID flow.Identifier) ([]flow.Identifier, error) {
+	response, err := client.GetCollection(
+		context.Background(),
+		&dps.GetCollectionRequest{
+			CollectionID: collectionID[:],
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get collection from the network")
+	}
+
+	codec := zbor.NewCodec()
+	var collection flow.LightCollection
+	err = codec.Unmarshal(response.Data, &collection)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed decoding collection")
+	}
+
+	return collection.Transactions, nil
+}
+
+var _ TransactionResolver = &BlockTransaction{}
+var _ TransactionIdentifier = &BlockTransaction{}
+
+// BlockTransaction implements transaction resolver that fetches the transaction
+// at the given index of the block at the given height.
+type BlockTransaction struct {
+	Client dps.APIClient
+	Height uint64
+	Index  int
+
+	network *NetworkTransactions
+}
+
+func (b *BlockTransaction) TransactionBody() (*flow.TransactionBody, error) {
+	network, err := b.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return network.TransactionBody()
+}
+
+func (b *BlockTransaction) BlockHeight() (uint64, error) {
+	return b.Height, nil
+}
+
+// TransactionID returns the ID of the transaction at the index.
+func (b *BlockTransaction) TransactionID() (flow.Identifier, error) {
+	network, err := b.resolve()
+	if err != nil {
+		return flow.ZeroID, err
+	}
+	return network.ID, nil
+}
+
+func (b *BlockTransaction) resolve() (*NetworkTransactions, error) {
+	if b.network != nil {
+		return b.network, nil
+	}
+
+	txIDs, err := ListBlockTransactions(b.Client, b.Height)
+	if err != nil {
+		return nil, err
+	}
+	if b.Index < 0 || b.Index >= len(txIDs) {
+		return nil, fmt.Errorf("transaction index %d out of range, block at height %d has %d transactions", b.Index, b.Height, len(txIDs))
+	}
+
+	b.network = &NetworkTransactions{
+		Client: b.Client,
+		ID:     txIDs[b.Index],
+	}
+	return b.network, nil
+}
+
+var _ TransactionResolver = &CollectionTransaction{}
+var _ TransactionIdentifier = &CollectionTransaction{}
+
+// CollectionTransaction implements transaction resolver that fetches the transaction
+// at the given index of a collection.
+type CollectionTransaction struct {
+	Client       dps.APIClient
+	CollectionID flow.Identifier
+	Index        int
+
+	network *NetworkTransactions
+}
+
+func (c *CollectionTransaction) TransactionBody() (*flow.TransactionBody, error) {
+	network, err := c.resolve()
+	if err != nil {
+		return nil, err
+	}
+	return network.TransactionBody()
+}
+
+func (c *CollectionTransaction) BlockHeight() (uint64, error) {
+	network, err := c.resolve()
+	if err != nil {
+		return 0, err
+	}
+	return network.BlockHeight()
+}
+
+// TransactionID returns the ID of the transaction at the index.
+func (c *CollectionTransaction) TransactionID() (flow.Identifier, error) {
+	network, err := c.resolve()
+	if err != nil {
+		return flow.ZeroID, err
+	}
+	return network.ID, nil
+}
+
+func (c *CollectionTransaction) resolve() (*NetworkTransactions, error) {
+	if c.network != nil {
+		return c.network, nil
+	}
+
+	txIDs, err := ListCollectionTransactions(c.Client, c.CollectionID)
+	if err != nil {
+		return nil, err
+	}
+	if c.Index < 0 || c.Index >= len(txIDs) {
+		return nil, fmt.Errorf("transaction index %d out of range, collection %s has %d transactions", c.Index, c.CollectionID, len(txIDs))
+	}
+
+	c.network = &NetworkTransactions{
+		Client: c.Client,
+		ID:     txIDs[c.Index],
+	}
+	return c.network, nil
+}
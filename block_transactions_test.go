@@ -0,0 +1,29 @@
+package debugger
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+	"testing"
+)
+
+func TestIndexedTransactionID(t *testing.T) {
+	networkID := flow.Identifier{1, 2, 3}
+
+	tests := []struct {
+		name       string
+		identifier TransactionIdentifier
+	}{
+		{name: "block transaction", identifier: &BlockTransaction{network: &NetworkTransactions{ID: networkID}}},
+		{name: "collection transaction", identifier: &CollectionTransaction{network: &NetworkTransactions{ID: networkID}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txID, err := tt.identifier.TransactionID()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if txID != networkID {
+				t.Errorf("transaction ID is %s, expected %s", txID, networkID)
+			}
+		})
+	}
+}
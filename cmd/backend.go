@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"github.com/onflow/execution-debugger"
+	"github.com/onflow/execution-debugger/debuggers"
+	"github.com/onflow/execution-debugger/registers"
+	"github.com/onflow/flow-dps/api/dps"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/execution"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"os"
+	"strings"
+)
+
+// backendFlags are the flags that select where transactions and registers are read from.
+type backendFlags struct {
+	hosts          string
+	crossCheckRate float64
+	accessHost     string
+	executionHost  string
+	checkpointFile string
+	walDir         string
+	commit         string
+	connConfig     debugger.ConnectionConfig
+	tokenFile      string
+}
+
+func newBackendFlags(fs *flag.FlagSet) *backendFlags {
+	f := &backendFlags{}
+	fs.StringVar(&f.hosts, "host", "", "host url with port, multiple archive hosts can be comma separated")
+	fs.Float64Var(&f.crossCheckRate, "cross-check", 0, "fraction of register reads to verify against a second archive host")
+	fs.StringVar(&f.accessHost, "access", "", "access node host url with port, used instead of the archive hosts")
+	fs.StringVar(&f.executionHost, "execution", "", "execution node host url with port, serves registers when using an access node")
+	fs.StringVar(&f.checkpointFile, "checkpoint", "", "execution state checkpoint file, registers are read from it instead of the network")
+	fs.StringVar(&f.walDir, "wal", "", "directory with WAL segments replayed on top of the checkpoint")
+	fs.StringVar(&f.commit, "commit", "", "state commitment to read from the checkpoint, defaults to the latest loaded state")
+
+	fs.BoolVar(&f.connConfig.TLS, "tls", false, "connect to the archive hosts using TLS")
+	fs.StringVar(&f.connConfig.CAFile, "tls-ca", "", "PEM file with the CA used to verify the archive hosts, system roots are used if empty")
+	fs.StringVar(&f.connConfig.CertFile, "tls-cert", "", "PEM client certificate for mutual TLS")
+	fs.StringVar(&f.connConfig.KeyFile, "tls-key", "", "PEM client key for mutual TLS")
+	fs.StringVar(&f.connConfig.ServerName, "tls-server-name", "", "override the server name used to verify the archive hosts")
+	fs.StringVar(&f.connConfig.Token, "token", "", "bearer token sent to the archive hosts, requires -tls")
+	fs.StringVar(&f.tokenFile, "token-file", "", "file containing the bearer token sent to the archive hosts, requires -tls")
+	return f
+}
+
+// backend holds the connections used to fetch transactions and registers.
+type backend struct {
+	chain flow.Chain
+
+	// dpsClients are set when using archive hosts
+	dpsClients []dps.APIClient
+	// accessClient is set when using an access node
+	accessClient access.AccessAPIClient
+
+	opts    []debuggers.TransactionDebuggerOption
+	closers []func() error
+}
+
+func (f *backendFlags) backend(chain flow.Chain) (*backend, error) {
+	b := &backend{
+		chain: chain,
+	}
+
+	if f.tokenFile != "" {
+		token, err := os.ReadFile(f.tokenFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read token file")
+		}
+		f.connConfig.Token = strings.TrimSpace(string(token))
+	}
+
+	err := b.connect(f)
+	if err != nil {
+		b.Close()
+		return nil, err
+	}
+
+	if f.checkpointFile != "" {
+		readerFactory, err := checkpointReaderFactory(f.checkpointFile, f.walDir, f.commit)
+		if err != nil {
+			b.Close()
+			return nil, errors.Wrap(err, "could not load checkpoint")
+		}
+		// the checkpoint is read at a state commitment, not at the height of the run, so the height keyed
+		// cache files would mix its registers with registers read from the network
+		b.opts = append(b.opts, debuggers.WithRegisterReaderFactory(readerFactory), debuggers.WithoutRegisterFileCache())
+	} else if f.hosts == "" && f.executionHost == "" {
+		return nil, fmt.Errorf("no source of registers given, use -host, -execution or -checkpoint")
+	}
+
+	return b, nil
+}
+
+func (b *backend) connect(f *backendFlags) error {
+	if f.accessHost != "" {
+		accessConn, err := debugger.Dial(f.accessHost, f.connConfig)
+		if err != nil {
+			return errors.Wrap(err, "could not connect to access node")
+		}
+		b.closers = append(b.closers, accessConn.Close)
+		b.accessClient = access.NewAccessAPIClient(accessConn)
+		b.opts = append(b.opts, debuggers.WithBlockHeaders(&debugger.AccessBlockHeaders{Client: b.accessClient}))
+
+		if f.executionHost != "" {
+			executionConn, err := debugger.Dial(f.executionHost, f.connConfig)
+			if err != nil {
+				return errors.Wrap(err, "could not connect to execution node")
+			}
+			b.closers = append(b.closers, executionConn.Close)
+
+			executionClient := execution.NewExecutionAPIClient(executionConn)
+			b.opts = append(b.opts, debuggers.WithRegisterReaderFactory(
+				registers.NewExecutionNodeReaderFactory(b.accessClient, executionClient),
+			))
+		}
+		return nil
+	}
+
+	if f.executionHost != "" {
+		return fmt.Errorf("an execution node can only be used together with an access node")
+	}
+	if f.hosts == "" {
+		// everything has to be available locally
+		return nil
+	}
+
+	hosts := strings.Split(f.hosts, ",")
+	for i := range hosts {
+		hosts[i] = strings.TrimSpace(hosts[i])
+	}
+	pool, err := debugger.NewArchivePool(hosts, f.connConfig)
+	if err != nil {
+		return errors.Wrap(err, "could not create archive connection pool")
+	}
+	b.closers = append(b.closers, pool.Close)
+
+	b.dpsClients, err = pool.Clients()
+	if err != nil {
+		return errors.Wrap(err, "could not connect to archive node")
+	}
+	b.opts = append(b.opts, debuggers.WithCrossCheckRate(f.crossCheckRate))
+	return nil
+}
+
+// networkTransaction returns a resolver of an existing transaction using the available node.
+func (b *backend) networkTransaction(txID flow.Identifier) (debugger.TransactionResolver, error) {
+	switch {
+	case b.accessClient != nil:
+		return &debugger.AccessTransactions{
+			Client: b.accessClient,
+			Chain:  b.chain,
+			ID:     txID,
+		}, nil
+	case len(b.dpsClients) > 0:
+		return &debugger.NetworkTransactions{
+			Client: b.dpsClients[0],
+			ID:     txID,
+		}, nil
+	default:
+		return nil, fmt.Errorf("fetching transaction %s requires an archive or access node", txID)
+	}
+}
+
+// archiveClient returns the client of the first archive host.
+func (b *backend) archiveClient() (dps.APIClient, error) {
+	if len(b.dpsClients) == 0 {
+		return nil, fmt.Errorf("an archive host is required, use -host")
+	}
+	return b.dpsClients[0], nil
+}
+
+// transactionDebugger creates a debugger for the transaction over this backend.
+func (b *backend) transactionDebugger(
+	txResolver debugger.TransactionResolver,
+	opts ...debuggers.TransactionDebuggerOption,
+) *debuggers.TransactionDebugger {
+	return debuggers.NewTransactionDebugger(
+		txResolver,
+		b.dpsClients,
+		b.chain,
+		log.Logger,
+		b.options(opts...)...,
+	)
+}
+
+// options returns the backend's debugger options followed by the given ones.
+func (b *backend) options(opts ...debuggers.TransactionDebuggerOption) []debuggers.TransactionDebuggerOption {
+	return append(append([]debuggers.TransactionDebuggerOption{}, b.opts...), opts...)
+}
+
+func (b *backend) Close() {
+	for _, closer := range b.closers {
+		err := closer()
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Msg("Could not close connection.")
+		}
+	}
+	b.closers = nil
+}
+
+// checkpointReaderFactory loads the local checkpoint (and WAL) and reads registers at the given commitment.
+func checkpointReaderFactory(checkpointFile string, walDir string, commit string) (registers.RegisterReaderFactory, error) {
+	var target flow.StateCommitment
+	if commit != "" {
+		commitBytes, err := hex.DecodeString(commit)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not decode state commitment")
+		}
+		target, err = flow.ToStateCommitment(commitBytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid state commitment")
+		}
+	}
+
+	ledger, err := registers.LoadCheckpointLedger(checkpointFile, walDir, target, log.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if commit == "" {
+		target = ledger.LatestCommit()
+	}
+	return ledger.ReaderFactory(target), nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/onflow/execution-debugger"
+	"github.com/onflow/execution-debugger/debuggers"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// batchFileEntry is a line of a JSONL batch file.
+// Paths are relative to the batch file.
+type batchFileEntry struct {
+	Name            string   `json:"name"`
+	Tx              string   `json:"tx"`
+	TxFile          string   `json:"txFile"`
+	ExecutionHeight uint64   `json:"executionHeight"`
+	Overrides       string   `json:"overrides"`
+	Patches         string   `json:"patches"`
+	Impersonate     []string `json:"impersonate"`
+}
+
+// batchCommand replays the transactions listed in a file.
+func batchCommand(args []string) {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	backendFlags := newBackendFlags(fs)
+
+	var file, directory, output string
+	var workers int
+	fs.StringVar(&file, "file", "", "file with one transaction ID per line, or JSONL entries with tx, txFile, executionHeight, overrides, patches and impersonate")
+	fs.IntVar(&workers, "workers", 4, "number of transactions replayed at the same time")
+	fs.StringVar(&directory, "out", "batch", "directory the summary and the run directories are written to")
+	fs.StringVar(&output, "output", "text", "output format, text or json")
+	_ = fs.Parse(args)
+
+	b, err := backendFlags.backend(flow.Mainnet.Chain())
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not set up backend.")
+		return
+	}
+	defer b.Close()
+
+	entries, err := readBatchFile(file, b)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not read batch file.")
+		return
+	}
+
+	batch := debuggers.NewBatchDebugger(b.dpsClients, b.chain, log.Logger, workers, directory, b.options()...)
+	results := batch.Run(context.Background(), entries)
+
+	if output == "json" {
+		printJSON(results)
+		return
+	}
+	err = batch.WriteSummary(results, os.Stdout)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not write summary.")
+	}
+}
+
+// readBatchFile reads the batch entries, lines starting with # are ignored.
+func readBatchFile(path string, b *backend) ([]debuggers.BatchEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var entries []debuggers.BatchEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var fileEntry batchFileEntry
+		if strings.HasPrefix(line, "{") {
+			err := json.Unmarshal([]byte(line), &fileEntry)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid entry on line %d", lineNumber)
+			}
+		} else {
+			fileEntry.Tx = line
+		}
+
+		entry, err := fileEntry.batchEntry(filepath.Dir(path), b)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid entry on line %d", lineNumber)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (e batchFileEntry) batchEntry(dir string, b *backend) (debuggers.BatchEntry, error) {
+	entry := debuggers.BatchEntry{
+		Name: e.Name,
+	}
+
+	switch {
+	case e.TxFile != "":
+		entry.Resolver = &debugger.FileTransaction{
+			Path: relativeTo(dir, e.TxFile),
+		}
+		if entry.Name == "" {
+			entry.Name = strings.TrimSuffix(filepath.Base(e.TxFile), filepath.Ext(e.TxFile))
+		}
+	case e.Tx != "":
+		txID, err := flow.HexStringToIdentifier(e.Tx)
+		if err != nil {
+			return entry, errors.Wrap(err, "could not parse transaction ID")
+		}
+		entry.Resolver, err = b.networkTransaction(txID)
+		if err != nil {
+			return entry, err
+		}
+		if entry.Name == "" {
+			entry.Name = txID.String()
+		}
+	default:
+		return entry, fmt.Errorf("entry has neither tx nor txFile")
+	}
+
+	if e.Overrides != "" {
+		overridden, err := debugger.LoadTransactionOverrides(relativeTo(dir, e.Overrides), entry.Resolver)
+		if err != nil {
+			return entry, errors.Wrap(err, "could not load transaction overrides")
+		}
+		entry.Resolver = overridden
+	}
+	if e.ExecutionHeight != 0 {
+		entry.Resolver = &debugger.ExecutionHeightOverride{
+			Resolver: entry.Resolver,
+			Height:   e.ExecutionHeight,
+		}
+	}
+
+	if e.Patches != "" {
+		patches, err := debugger.LoadStatePatches(relativeTo(dir, e.Patches))
+		if err != nil {
+			return entry, errors.Wrap(err, "could not load state patches")
+		}
+		entry.Options = append(entry.Options, debuggers.WithStatePatches(patches))
+	}
+
+	if len(e.Impersonate) > 0 {
+		addresses, err := parseAddresses(e.Impersonate)
+		if err != nil {
+			return entry, errors.Wrap(err, "invalid impersonated account")
+		}
+		entry.Options = append(entry.Options, debuggers.WithRemoteDebuggerOptions(debuggers.WithImpersonatedAccounts(addresses...)))
+	}
+
+	return entry, nil
+}
+
+func relativeTo(dir string, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/onflow/execution-debugger/debuggers"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"os"
+	"strings"
+)
+
+// bisectCommand finds the first height in a range where the outcome of a transaction or script changes.
+func bisectCommand(args []string) {
+	fs := flag.NewFlagSet("bisect", flag.ExitOnError)
+	backendFlags := newBackendFlags(fs)
+	txFlags := newTransactionFlags(fs)
+
+	var startHeight, endHeight uint64
+	var scriptFile, argumentsFile string
+	var target debuggers.BisectTarget
+	fs.Uint64Var(&startHeight, "start", 0, "first height of the range, defaults to the height of the transaction")
+	fs.Uint64Var(&endHeight, "end", 0, "last height of the range")
+	fs.StringVar(&scriptFile, "script", "", ".cdc script to bisect instead of a transaction")
+	fs.StringVar(&argumentsFile, "args", "", "JSON file with an array of JSON-Cadence script arguments")
+	fs.BoolVar(&target.CompareEventPayloads, "event-payloads", false, "compare the event payloads too, by default only the event types are compared")
+	_ = fs.Parse(args)
+
+	b, err := backendFlags.backend(flow.Mainnet.Chain())
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not set up backend.")
+		return
+	}
+	defer b.Close()
+
+	if scriptFile != "" {
+		target.Script, target.Arguments, err = readScript(scriptFile, argumentsFile)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("Could not read script.")
+			return
+		}
+	} else {
+		txResolver, err := txFlags.resolver(b)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("Could not resolve transaction.")
+			return
+		}
+		err = target.ResolveTransaction(txResolver)
+		if err == nil && startHeight == 0 {
+			startHeight, err = txResolver.BlockHeight()
+		}
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("Could not resolve transaction.")
+			return
+		}
+	}
+
+	opts, err := txFlags.options()
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not set up debugger.")
+		return
+	}
+
+	result, err := debuggers.
+		NewBisector(target, b.dpsClients, b.chain, log.Logger, b.options(opts...)...).
+		Bisect(startHeight, endHeight)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not bisect.")
+		return
+	}
+
+	printBisectResult(result)
+}
+
+func printBisectResult(result *debuggers.BisectResult) {
+	if !result.Changed {
+		fmt.Printf("outcome is the same at heights %d and %d\n", result.Before.Height, result.After.Height)
+		printBisectOutcome(result.Before)
+		return
+	}
+
+	fmt.Printf("outcome changed at height %d\n", result.After.Height)
+	printBisectOutcome(result.Before)
+	printBisectOutcome(result.After)
+
+	fmt.Printf("registers that differ between heights %d and %d:\n", result.Before.Height, result.After.Height)
+	for _, diff := range result.Registers {
+		fmt.Printf("  [%s] %s\n    before: %s\n    after:  %s\n", diff.Owner, diff.Key, diff.Before, diff.After)
+	}
+}
+
+func printBisectOutcome(outcome *debuggers.BisectOutcome) {
+	fmt.Printf("height %d:\n", outcome.Height)
+	if outcome.Error != "" {
+		fmt.Printf("  error: %s\n", outcome.Error)
+	}
+	if outcome.Value != "" {
+		fmt.Printf("  value: %s\n", outcome.Value)
+	}
+	for _, event := range outcome.Events {
+		fmt.Printf("  event: %s: %s\n", event.Type, strings.TrimSpace(event.Payload))
+	}
+}
+
+// readScript reads the script code and its JSON-Cadence arguments.
+func readScript(scriptFile string, argumentsFile string) ([]byte, [][]byte, error) {
+	code, err := os.ReadFile(scriptFile)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not read script file")
+	}
+	if argumentsFile == "" {
+		return code, nil, nil
+	}
+
+	data, err := os.ReadFile(argumentsFile)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not read arguments file")
+	}
+	var rawArguments []json.RawMessage
+	err = json.Unmarshal(data, &rawArguments)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "could not decode arguments file")
+	}
+
+	arguments := make([][]byte, 0, len(rawArguments))
+	for _, argument := range rawArguments {
+		arguments = append(arguments, argument)
+	}
+	return code, arguments, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/onflow/execution-debugger"
+	"github.com/onflow/execution-debugger/debuggers"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog/log"
+)
+
+// gasCommand finds the minimum gas limit a transaction succeeds with.
+func gasCommand(args []string) {
+	fs := flag.NewFlagSet("gas", flag.ExitOnError)
+	backendFlags := newBackendFlags(fs)
+	txFlags := newTransactionFlags(fs)
+
+	var maxLimit uint64
+	fs.Uint64Var(&maxLimit, "max-gas", debugger.DefaultGasLimit, "highest gas limit to try")
+	_ = fs.Parse(args)
+
+	b, err := backendFlags.backend(flow.Mainnet.Chain())
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not set up backend.")
+		return
+	}
+	defer b.Close()
+
+	txResolver, err := txFlags.resolver(b)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not resolve transaction.")
+		return
+	}
+
+	opts, err := txFlags.options()
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not set up debugger.")
+		return
+	}
+
+	result, err := debuggers.
+		NewGasLimitSearch(txResolver, b.dpsClients, b.chain, log.Logger, maxLimit, b.options(opts...)...).
+		Search()
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not search gas limit.")
+		return
+	}
+
+	printGasLimitSearchResult(result)
+}
+
+func printGasLimitSearchResult(result *debuggers.GasLimitSearchResult) {
+	if result.Succeeded {
+		fmt.Printf("minimum gas limit: %d (original %d, %d runs)\n", result.MinimumLimit, result.OriginalLimit, result.Runs)
+	} else {
+		fmt.Printf("transaction fails with the maximum gas limit %d: %s\n", result.MinimumLimit, result.Error)
+	}
+	fmt.Printf("computation used: %d\n", result.Metering.ComputationUsed)
+	fmt.Printf("memory estimate: %d\n", result.Metering.MemoryEstimate)
+
+	fmt.Println("computation intensities:")
+	printIntensities(result.Metering.ComputationIntensities, debuggers.ComputationKindName)
+	fmt.Println("memory intensities:")
+	printIntensities(result.Metering.MemoryIntensities, debuggers.MemoryKindName)
+}
+
+// printIntensities prints the intensities sorted from the highest.
+func printIntensities(intensities map[uint64]uint64, name func(uint64) string) {
+	for _, kind := range debuggers.SortedKinds(intensities) {
+		fmt.Printf("  %-30s %d\n", name(kind), intensities[kind])
+	}
+}
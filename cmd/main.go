@@ -2,70 +2,127 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
-	"github.com/onflow/execution-debugger"
+	"fmt"
 	"github.com/onflow/execution-debugger/debuggers"
-	"github.com/onflow/flow-dps/api/dps"
 	"github.com/onflow/flow-go/model/flow"
-	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 	"os"
 )
 
+// commands are the subcommands, without a subcommand a single transaction is run.
+var commands = map[string]func(args []string){
+	"bisect":   bisectCommand,
+	"batch":    batchCommand,
+	"gas":      gasCommand,
+	"scan":     scanCommand,
+	"scenario": scenarioCommand,
+	"suite":    suiteCommand,
+	"upgrade":  upgradeCommand,
+	"usage":    usageCommand,
+}
+
 func main() {
 	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 
-	var host string
-	flag.StringVar(&host, "host", "", "host url with port")
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			command(os.Args[2:])
+			return
+		}
+	}
 
-	var tx string
-	flag.StringVar(&tx, "tx", "", "transaction id")
+	backendFlags := newBackendFlags(flag.CommandLine)
+	txFlags := newTransactionFlags(flag.CommandLine)
+	var output string
+	flag.StringVar(&output, "output", "text", "output format, text or json")
 
 	flag.Parse()
 
-	txid, err := flow.HexStringToIdentifier(tx)
+	chain := flow.Mainnet.Chain()
+	ctx := context.Background()
+
+	b, err := backendFlags.backend(chain)
 	if err != nil {
 		log.Error().
 			Err(err).
-			Msg("Could not parse transaction ID.")
+			Msg("Could not set up backend.")
 		return
 	}
+	defer b.Close()
 
-	chain := flow.Mainnet.Chain()
-	ctx := context.Background()
+	if txFlags.list {
+		err := txFlags.listTransactions(b)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("Could not list transactions.")
+		}
+		return
+	}
 
-	conn, err := grpc.Dial(
-		host,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	txResolver, err := txFlags.resolver(b)
 	if err != nil {
-		err = errors.Wrap(err, "could not connect to archive node")
-		panic(err)
+		log.Error().
+			Err(err).
+			Msg("Could not resolve transaction.")
+		return
 	}
-	client := dps.NewAPIClient(conn)
 
-	txResolver := &debugger.NetworkTransactions{
-		Client: client,
-		ID:     txid,
+	opts, err := txFlags.options()
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not set up debugger.")
+		return
 	}
 
-	txErr, err := debuggers.
-		NewTransactionDebugger(txResolver, host, client, chain, log.Logger).
+	result, err := b.
+		transactionDebugger(txResolver, opts...).
 		RunTransaction(ctx)
 
-	if txErr != nil {
+	if output == "json" && result != nil {
+		printJSON(result)
+		return
+	}
+	logResult(result, err)
+}
+
+func logResult(result *debuggers.RunResult, err error) {
+	if result == nil {
 		log.Error().
-			Err(txErr).
-			Msg("Transaction error.")
+			Err(err).
+			Msg("Could not run transaction.")
 		return
 	}
-	if err != nil {
+
+	switch result.Status {
+	case debuggers.RunStatusFailed:
 		log.Error().
-			Err(txErr).
+			Uint16("code", result.Error.Code).
+			Str("error", result.Error.Message).
+			Msg("Transaction error.")
+	case debuggers.RunStatusError:
+		log.Error().
+			Err(err).
 			Msg("Implementation error.")
+	default:
+		log.Info().
+			Str("directory", result.Directory).
+			Msg("Transaction executed.")
+	}
+}
+
+// printJSON prints the value as indented JSON to stdout.
+func printJSON(v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not encode output.")
 		return
 	}
+	fmt.Println(string(data))
 }
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/onflow/execution-debugger/debuggers"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog/log"
+	"os"
+)
+
+// scanCommand re-executes the failed transactions of a height range and groups them by cause.
+func scanCommand(args []string) {
+	fs := flag.NewFlagSet("scan", flag.ExitOnError)
+	backendFlags := newBackendFlags(fs)
+
+	var startHeight, endHeight uint64
+	var directory, output string
+	var workers int
+	fs.Uint64Var(&startHeight, "start", 0, "first block height to scan")
+	fs.Uint64Var(&endHeight, "end", 0, "last block height to scan")
+	fs.IntVar(&workers, "workers", 4, "number of transactions replayed at the same time")
+	fs.StringVar(&directory, "out", "scan", "directory the report and the run directories are written to")
+	fs.StringVar(&output, "output", "text", "output format, text or json")
+	_ = fs.Parse(args)
+
+	b, err := backendFlags.backend(flow.Mainnet.Chain())
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not set up backend.")
+		return
+	}
+	defer b.Close()
+
+	client, err := b.archiveClient()
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not set up scan.")
+		return
+	}
+
+	batch := debuggers.NewBatchDebugger(b.dpsClients, b.chain, log.Logger, workers, directory, b.options()...)
+	scanner := debuggers.NewScanner(client, batch, log.Logger)
+	report, err := scanner.Scan(context.Background(), startHeight, endHeight)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not scan blocks.")
+		return
+	}
+
+	if output == "json" {
+		printJSON(report)
+		return
+	}
+	err = scanner.WriteReport(report, os.Stdout)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not write report.")
+	}
+}
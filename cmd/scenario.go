@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/onflow/execution-debugger"
+	"github.com/onflow/execution-debugger/debuggers"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog/log"
+	"os"
+)
+
+// scenarioCommand runs the steps of a scenario on the state of one height and exits with status 1 if a step fails.
+func scenarioCommand(args []string) {
+	fs := flag.NewFlagSet("scenario", flag.ExitOnError)
+	backendFlags := newBackendFlags(fs)
+
+	var file, patchesFile, directory, output string
+	var fvmLogs bool
+	fs.StringVar(&file, "file", "", "scenario file with the height and the steps")
+	fs.StringVar(&patchesFile, "patches", "", "JSON file with state patches applied before the first step")
+	fs.StringVar(&directory, "out", "scenario", "directory the report and the run artifacts are written to")
+	fs.StringVar(&output, "output", "text", "output format, text or json")
+	fs.BoolVar(&fvmLogs, "fvm-logs", false, "print the logs of the FVM to stderr")
+	_ = fs.Parse(args)
+
+	scenario, err := debuggers.LoadScenario(file)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not load scenario.")
+		os.Exit(1)
+	}
+
+	b, err := backendFlags.backend(flow.Mainnet.Chain())
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not set up backend.")
+		os.Exit(1)
+	}
+	defer b.Close()
+
+	opts := []debuggers.TransactionDebuggerOption{debuggers.WithDirectory(directory)}
+	if fvmLogs {
+		opts = append(opts, debuggers.WithFVMLogs(os.Stderr))
+	}
+	if patchesFile != "" {
+		patches, err := debugger.LoadStatePatches(patchesFile)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("Could not load state patches.")
+			b.Close()
+			os.Exit(1)
+		}
+		opts = append(opts, debuggers.WithStatePatches(patches))
+	}
+	runner := debuggers.NewScenarioRunner(b.dpsClients, b.chain, log.Logger, b.options(opts...)...)
+	report, err := runner.Run(context.Background(), scenario, func(scenario *debuggers.Scenario, step debuggers.ScenarioStep) (debugger.TransactionResolver, error) {
+		if step.TransactionFile != "" {
+			return &debugger.FileTransaction{
+				Path:   scenario.Path(step.TransactionFile),
+				Height: scenario.Height,
+			}, nil
+		}
+		return transactionByID(b, step.Transaction)
+	})
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not run scenario.")
+		b.Close()
+		os.Exit(1)
+	}
+
+	if output == "json" {
+		printJSON(report)
+	} else {
+		err = runner.WriteReport(report, os.Stdout)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("Could not write report.")
+		}
+	}
+	if report.Failed > 0 {
+		b.Close()
+		os.Exit(1)
+	}
+}
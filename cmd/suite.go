@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/onflow/execution-debugger"
+	"github.com/onflow/execution-debugger/debuggers"
+	"github.com/onflow/execution-debugger/registers"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"os"
+)
+
+// suiteCommand runs a regression suite and exits with status 1 if a case does not match its expectation.
+func suiteCommand(args []string) {
+	fs := flag.NewFlagSet("suite", flag.ExitOnError)
+	backendFlags := newBackendFlags(fs)
+
+	var file, offline, directory, output string
+	var update bool
+	var tolerance float64
+	var workers int
+	fs.StringVar(&file, "file", "", "suite file with the cases and their expected outcomes")
+	fs.StringVar(&offline, "offline", "", "directory with recorded register cache files, replaces the backend flags; transactions must be given as files")
+	fs.BoolVar(&update, "update", false, "save the outcomes as the expectations of the suite file instead of checking them")
+	fs.Float64Var(&tolerance, "tolerance", 0.1, "fraction the computation may differ from the saved outcome when using -update")
+	fs.IntVar(&workers, "workers", 4, "number of transactions replayed at the same time")
+	fs.StringVar(&directory, "out", "suite", "directory the report and the run directories are written to")
+	fs.StringVar(&output, "output", "text", "output format, text or json")
+	_ = fs.Parse(args)
+
+	suite, err := debuggers.LoadSuite(file)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not load suite.")
+		os.Exit(1)
+	}
+
+	var b *backend
+	if offline != "" {
+		b = &backend{
+			chain: flow.Mainnet.Chain(),
+			opts: []debuggers.TransactionDebuggerOption{
+				debuggers.WithRegisterReaderFactory(registers.NewOfflineReaderFactory(offline)),
+				// the offline registers are already the register cache files
+				debuggers.WithoutRegisterFileCache(),
+			},
+		}
+	} else {
+		b, err = backendFlags.backend(flow.Mainnet.Chain())
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("Could not set up backend.")
+			os.Exit(1)
+		}
+	}
+	defer b.Close()
+
+	batch := debuggers.NewBatchDebugger(b.dpsClients, b.chain, log.Logger, workers, directory, b.options()...)
+	runner := debuggers.NewSuiteRunner(batch, log.Logger)
+	report := runner.Run(context.Background(), suite, func(suite *debuggers.Suite, c debuggers.SuiteCase) (debugger.TransactionResolver, error) {
+		return suiteCaseResolver(b, suite, c)
+	})
+
+	if update {
+		report.Update(suite, tolerance)
+		err := suite.Write(file)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("Could not write suite.")
+			os.Exit(1)
+		}
+		fmt.Printf("updated %d cases\n", len(suite.Cases))
+		return
+	}
+
+	if output == "json" {
+		printJSON(report)
+	} else {
+		err = runner.WriteReport(report, os.Stdout)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("Could not write report.")
+		}
+	}
+	if report.Failed > 0 {
+		b.Close()
+		os.Exit(1)
+	}
+}
+
+// suiteCaseResolver returns the resolver of a transaction case.
+func suiteCaseResolver(b *backend, suite *debuggers.Suite, c debuggers.SuiteCase) (debugger.TransactionResolver, error) {
+	if c.TransactionFile != "" {
+		return &debugger.FileTransaction{
+			Path:   suite.Path(c.TransactionFile),
+			Height: c.Height,
+		}, nil
+	}
+	return transactionByID(b, c.Transaction)
+}
+
+// transactionByID returns the resolver of the network transaction with the given ID.
+func transactionByID(b *backend, id string) (debugger.TransactionResolver, error) {
+	if id == "" {
+		return nil, fmt.Errorf("neither a transaction ID nor a transaction file is given")
+	}
+	txID, err := flow.HexStringToIdentifier(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse transaction ID")
+	}
+	return b.networkTransaction(txID)
+}
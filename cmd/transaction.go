@@ -0,0 +1,178 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/onflow/execution-debugger"
+	"github.com/onflow/execution-debugger/debuggers"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/pkg/errors"
+	"os"
+	"strings"
+)
+
+// transactionFlags are the flags that select the transaction to run and how it is modified.
+type transactionFlags struct {
+	tx            string
+	txFile        string
+	height        uint64
+	index         int
+	collection    string
+	list          bool
+	impersonate   string
+	overridesFile string
+	patchesFile   string
+	execHeight    uint64
+	fvmLogs       bool
+}
+
+func newTransactionFlags(fs *flag.FlagSet) *transactionFlags {
+	f := &transactionFlags{}
+	fs.StringVar(&f.tx, "tx", "", "transaction id")
+	fs.StringVar(&f.txFile, "tx-file", "", "locally authored transaction to run instead of -tx: a JSON manifest, a JSON transaction body or an RLP encoded transaction")
+	fs.Uint64Var(&f.height, "height", 0, "block height of the -index transaction, or to execute the -tx-file transaction at, overrides the height in the file")
+	fs.IntVar(&f.index, "index", -1, "index of the transaction in the block at -height or in the -collection")
+	fs.StringVar(&f.collection, "collection", "", "collection id, selects the transaction at -index in the collection")
+	fs.BoolVar(&f.list, "list", false, "list the transactions of the block at -height or of the -collection instead of running one")
+	fs.StringVar(&f.impersonate, "impersonate", "", "comma separated accounts that can propose, pay or authorize without signatures, turns on signature and sequence number checks for all other accounts, see the README")
+	fs.StringVar(&f.overridesFile, "overrides", "", "JSON file with script, argument, gas limit or authorizer overrides applied to the transaction")
+	fs.StringVar(&f.patchesFile, "patches", "", "JSON file with register, FLOW balance or storage patches applied to the state before execution")
+	fs.Uint64Var(&f.execHeight, "execution-height", 0, "execute the transaction against the state and block header at this height instead of its own")
+	fs.BoolVar(&f.fvmLogs, "fvm-logs", false, "print the logs of the FVM to stderr")
+	return f
+}
+
+// resolver returns the resolver of the transaction selected by the flags.
+func (f *transactionFlags) resolver(b *backend) (debugger.TransactionResolver, error) {
+	var txResolver debugger.TransactionResolver
+	switch {
+	case f.txFile != "":
+		txResolver = &debugger.FileTransaction{
+			Path:   f.txFile,
+			Height: f.height,
+		}
+	case f.collection != "":
+		collectionID, err := flow.HexStringToIdentifier(f.collection)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not parse collection ID")
+		}
+		client, err := b.archiveClient()
+		if err != nil {
+			return nil, err
+		}
+		index := f.index
+		if index < 0 {
+			index = 0
+		}
+		txResolver = &debugger.CollectionTransaction{
+			Client:       client,
+			CollectionID: collectionID,
+			Index:        index,
+		}
+	case f.index >= 0:
+		client, err := b.archiveClient()
+		if err != nil {
+			return nil, err
+		}
+		txResolver = &debugger.BlockTransaction{
+			Client: client,
+			Height: f.height,
+			Index:  f.index,
+		}
+	default:
+		txID, err := flow.HexStringToIdentifier(f.tx)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not parse transaction ID")
+		}
+		txResolver, err = b.networkTransaction(txID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if f.overridesFile != "" {
+		overridden, err := debugger.LoadTransactionOverrides(f.overridesFile, txResolver)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not load transaction overrides")
+		}
+		txResolver = overridden
+	}
+
+	if f.execHeight != 0 {
+		txResolver = &debugger.ExecutionHeightOverride{
+			Resolver: txResolver,
+			Height:   f.execHeight,
+		}
+	}
+
+	return txResolver, nil
+}
+
+// listTransactions prints the transactions of the block at -height or of the -collection.
+func (f *transactionFlags) listTransactions(b *backend) error {
+	client, err := b.archiveClient()
+	if err != nil {
+		return err
+	}
+
+	var txIDs []flow.Identifier
+	if f.collection != "" {
+		collectionID, err := flow.HexStringToIdentifier(f.collection)
+		if err != nil {
+			return errors.Wrap(err, "could not parse collection ID")
+		}
+		txIDs, err = debugger.ListCollectionTransactions(client, collectionID)
+		if err != nil {
+			return err
+		}
+	} else {
+		txIDs, err = debugger.ListBlockTransactions(client, f.height)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, txID := range txIDs {
+		fmt.Printf("%d\t%s\n", i, txID)
+	}
+	return nil
+}
+
+// options returns the debugger options selected by the flags.
+func (f *transactionFlags) options() ([]debuggers.TransactionDebuggerOption, error) {
+	var opts []debuggers.TransactionDebuggerOption
+	if f.impersonate != "" {
+		addresses, err := parseAddresses(strings.Split(f.impersonate, ","))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid impersonated account")
+		}
+		opts = append(opts, debuggers.WithRemoteDebuggerOptions(debuggers.WithImpersonatedAccounts(addresses...)))
+	}
+	if f.fvmLogs {
+		opts = append(opts, debuggers.WithFVMLogs(os.Stderr))
+	}
+	if f.patchesFile != "" {
+		patches, err := debugger.LoadStatePatches(f.patchesFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not load state patches")
+		}
+		opts = append(opts, debuggers.WithStatePatches(patches))
+	}
+	return opts, nil
+}
+
+// parseAddresses decodes hex encoded addresses, an empty address is an error.
+func parseAddresses(hexAddresses []string) ([]flow.Address, error) {
+	addresses := make([]flow.Address, 0, len(hexAddresses))
+	for _, hexAddress := range hexAddresses {
+		address, err := debugger.ParseAddress(strings.TrimSpace(hexAddress))
+		if err != nil {
+			return nil, err
+		}
+		if address == flow.EmptyAddress {
+			return nil, fmt.Errorf("empty address %q", hexAddress)
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, nil
+}
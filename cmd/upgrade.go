@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/onflow/execution-debugger/debuggers"
+	"github.com/onflow/execution-debugger/registers"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"os"
+)
+
+// upgradeCommand runs transactions with the deployed and with an upgraded contract and reports the differences.
+func upgradeCommand(args []string) {
+	fs := flag.NewFlagSet("upgrade", flag.ExitOnError)
+	backendFlags := newBackendFlags(fs)
+
+	var address, contract, codeFile, file, directory, output string
+	var startHeight, endHeight uint64
+	var workers int
+	fs.StringVar(&address, "address", "", "address of the account the contract is deployed on")
+	fs.StringVar(&contract, "contract", "", "name of the contract")
+	fs.StringVar(&codeFile, "code", "", "file with the upgraded contract code")
+	fs.StringVar(&file, "file", "", "batch file with the transactions to run, see the batch command")
+	fs.Uint64Var(&startHeight, "start", 0, "first block height of the transactions to run, if no -file is given")
+	fs.Uint64Var(&endHeight, "end", 0, "last block height of the transactions to run, if no -file is given")
+	fs.IntVar(&workers, "workers", 4, "number of transactions run at the same time")
+	fs.StringVar(&directory, "out", "upgrade", "directory the report and the run directories are written to")
+	fs.StringVar(&output, "output", "text", "output format, text or json")
+	_ = fs.Parse(args)
+
+	target, err := contractTarget(address, contract)
+	if err == nil && target.Contract == "" {
+		err = errors.New("a contract name is required, use -contract")
+	}
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Invalid contract.")
+		return
+	}
+	code, err := os.ReadFile(codeFile)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not read contract code.")
+		return
+	}
+	override := registers.NewContractCodeOverride()
+	override.Set(target.Address, target.Contract, code)
+
+	b, err := backendFlags.backend(flow.Mainnet.Chain())
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not set up backend.")
+		return
+	}
+	defer b.Close()
+
+	var entries []debuggers.BatchEntry
+	if file != "" {
+		entries, err = readBatchFile(file, b)
+	} else {
+		entries, err = blockRangeEntries(b, startHeight, endHeight)
+	}
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not list transactions.")
+		return
+	}
+
+	batch := debuggers.NewBatchDebugger(b.dpsClients, b.chain, log.Logger, workers, directory, b.options()...)
+	analyzer := debuggers.NewUpgradeAnalyzer(batch, override, log.Logger)
+	report := analyzer.Analyze(context.Background(), entries)
+
+	if output == "json" {
+		printJSON(report)
+		return
+	}
+	err = analyzer.WriteReport(report, os.Stdout)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not write report.")
+	}
+}
+
+// blockRangeEntries lists the transactions of the blocks from startHeight to endHeight.
+func blockRangeEntries(b *backend, startHeight, endHeight uint64) ([]debuggers.BatchEntry, error) {
+	client, err := b.archiveClient()
+	if err != nil {
+		return nil, err
+	}
+	return debuggers.BlockRangeEntries(context.Background(), client, startHeight, endHeight)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/onflow/execution-debugger/debuggers"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog/log"
+	"os"
+)
+
+// usageCommand finds the transactions of a height range that used a contract or an account.
+func usageCommand(args []string) {
+	fs := flag.NewFlagSet("usage", flag.ExitOnError)
+	backendFlags := newBackendFlags(fs)
+
+	var address, contract, directory, output string
+	var startHeight, endHeight uint64
+	var workers int
+	fs.StringVar(&address, "address", "", "address of the account")
+	fs.StringVar(&contract, "contract", "", "name of the contract, all registers of the account are matched if empty")
+	fs.Uint64Var(&startHeight, "start", 0, "first block height to scan")
+	fs.Uint64Var(&endHeight, "end", 0, "last block height to scan")
+	fs.IntVar(&workers, "workers", 4, "number of transactions replayed at the same time")
+	fs.StringVar(&directory, "out", "usage", "directory the report and the run directories are written to")
+	fs.StringVar(&output, "output", "text", "output format, text or json")
+	_ = fs.Parse(args)
+
+	target, err := contractTarget(address, contract)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Invalid target.")
+		return
+	}
+
+	b, err := backendFlags.backend(flow.Mainnet.Chain())
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not set up backend.")
+		return
+	}
+	defer b.Close()
+
+	client, err := b.archiveClient()
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not set up scan.")
+		return
+	}
+
+	batch := debuggers.NewBatchDebugger(b.dpsClients, b.chain, log.Logger, workers, directory, b.options()...)
+	scanner := debuggers.NewContractUsageScanner(client, batch, target, log.Logger)
+	report, err := scanner.Scan(context.Background(), startHeight, endHeight)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not scan blocks.")
+		return
+	}
+
+	if output == "json" {
+		printJSON(report)
+		return
+	}
+	err = scanner.WriteReport(report, os.Stdout)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Could not write report.")
+	}
+}
+
+func contractTarget(address string, contract string) (debuggers.ContractTarget, error) {
+	if address == "" {
+		return debuggers.ContractTarget{}, fmt.Errorf("an address is required, use -address")
+	}
+	a := flow.HexToAddress(address)
+	if a == flow.EmptyAddress {
+		return debuggers.ContractTarget{}, fmt.Errorf("invalid address %s", address)
+	}
+	return debuggers.ContractTarget{
+		Address:  a,
+		Contract: contract,
+	}, nil
+}
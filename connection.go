@@ -0,0 +1,111 @@
+package debugger
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"os"
+)
+
+// ConnectionConfig describes how to connect to an archive node.
+// The zero value is a plaintext connection without credentials.
+type ConnectionConfig struct {
+	// TLS enables transport security. Server certificates are verified against the system roots
+	// unless CAFile is set.
+	TLS bool
+	// CAFile is a PEM file with the certificate authorities used to verify the server.
+	CAFile string
+	// CertFile and KeyFile are a PEM client certificate and key used for mutual TLS.
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the server name used to verify the server certificate.
+	ServerName string
+
+	// Token is sent as a bearer token with every request. It requires TLS.
+	Token string
+}
+
+// DialOptions returns the grpc dial options for the config.
+func (c ConnectionConfig) DialOptions() ([]grpc.DialOption, error) {
+	if !c.TLS {
+		if c.Token != "" {
+			return nil, fmt.Errorf("a bearer token can only be sent over a TLS connection")
+		}
+		return []grpc.DialOption{
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		}, nil
+	}
+
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+	}
+	if c.Token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(c.Token)))
+	}
+	return opts, nil
+}
+
+func (c ConnectionConfig) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: c.ServerName,
+	}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", c.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		if c.CertFile == "" || c.KeyFile == "" {
+			return nil, fmt.Errorf("both a client certificate and a key are required for mutual TLS")
+		}
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// Dial connects to the archive node at host using the config.
+func Dial(host string, config ConnectionConfig) (*grpc.ClientConn, error) {
+	opts, err := config.DialOptions()
+	if err != nil {
+		return nil, err
+	}
+	return grpc.Dial(host, opts...)
+}
+
+// bearerToken implements per-RPC credentials that send a bearer token in the authorization header.
+type bearerToken string
+
+var _ credentials.PerRPCCredentials = bearerToken("")
+
+func (t bearerToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{
+		"authorization": "Bearer " + string(t),
+	}, nil
+}
+
+func (t bearerToken) RequireTransportSecurity() bool {
+	return true
+}
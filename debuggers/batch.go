@@ -0,0 +1,188 @@
+package debuggers
+
+import (
+	"context"
+	"fmt"
+	"github.com/onflow/execution-debugger"
+	"github.com/onflow/execution-debugger/registers"
+	"github.com/onflow/flow-dps/api/dps"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog"
+	"io"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+)
+
+const summaryFilename = "summary.csv"
+
+// BatchEntry is one transaction of a batch.
+// Options are applied after the options of the batch.
+type BatchEntry struct {
+	Name     string
+	Resolver debugger.TransactionResolver
+	Options  []TransactionDebuggerOption
+}
+
+// BatchResult is the outcome of one batch entry,
+// Result is nil if the run could not be set up.
+type BatchResult struct {
+	Name   string     `json:"name"`
+	Result *RunResult `json:"result,omitempty"`
+	Error  string     `json:"error,omitempty"`
+}
+
+// BatchDebugger replays many transactions with a bounded number of concurrent runs.
+// The runs share the archive clients and the register caches,
+// each run writes its artifacts to its own directory.
+type BatchDebugger struct {
+	dpsClients []dps.APIClient
+	chain      flow.Chain
+	workers    int
+	directory  string
+	opts       []TransactionDebuggerOption
+	log        zerolog.Logger
+}
+
+func NewBatchDebugger(
+	dpsClients []dps.APIClient,
+	chain flow.Chain,
+	logger zerolog.Logger,
+	workers int,
+	directory string,
+	opts ...TransactionDebuggerOption) *BatchDebugger {
+	if workers < 1 {
+		workers = 1
+	}
+	return &BatchDebugger{
+		dpsClients: dpsClients,
+		chain:      chain,
+		workers:    workers,
+		directory:  directory,
+		opts:       opts,
+		log:        logger,
+	}
+}
+
+// Run replays the entries and returns their results in the order of the entries.
+func (b *BatchDebugger) Run(ctx context.Context, entries []BatchEntry) []BatchResult {
+	caches := registers.NewRegisterCacheSet(b.log)
+	defer func() {
+		err := caches.Close()
+		if err != nil {
+			b.log.Warn().
+				Err(err).
+				Msg("Could not close register caches.")
+		}
+	}()
+
+	results := make([]BatchResult, len(entries))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < b.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = b.run(ctx, caches, i, entries[i])
+			}
+		}()
+	}
+
+	for i := range entries {
+		if ctx.Err() != nil {
+			results[i] = BatchResult{Name: entries[i].Name, Error: ctx.Err().Error()}
+			continue
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+func (b *BatchDebugger) run(ctx context.Context, caches *registers.RegisterCacheSet, i int, entry BatchEntry) BatchResult {
+	name := entry.Name
+	if name == "" {
+		name = strconv.Itoa(i)
+	}
+
+	opts := append([]TransactionDebuggerOption{}, b.opts...)
+	opts = append(opts,
+		WithRegisterCaches(caches),
+		WithDirectory(filepath.Join(b.directory, fmt.Sprintf("%04d_%s", i, name))),
+	)
+	opts = append(opts, entry.Options...)
+
+	logger := b.log.With().Str("entry", name).Logger()
+	result, err := NewTransactionDebugger(entry.Resolver, b.dpsClients, b.chain, logger, opts...).
+		RunTransaction(ctx)
+
+	batchResult := BatchResult{
+		Name:   name,
+		Result: result,
+	}
+	if err != nil {
+		batchResult.Error = err.Error()
+		logger.Warn().
+			Err(err).
+			Msg("Could not run transaction.")
+	}
+	return batchResult
+}
+
+// summaryRows are the rows of the summary table, starting with the header.
+func summaryRows(results []BatchResult) [][]string {
+	rows := [][]string{{"Name", "Transaction", "Height", "Status", "Error Code", "Computation", "Duration", "Directory"}}
+	for _, r := range results {
+		if r.Result == nil {
+			rows = append(rows, []string{r.Name, "", "", string(RunStatusError), "", "", "", r.Error})
+			continue
+		}
+
+		code := ""
+		if r.Result.Error != nil && r.Result.Error.Code != 0 {
+			code = strconv.Itoa(int(r.Result.Error.Code))
+		}
+		computation := ""
+		if r.Result.Metering != nil {
+			computation = strconv.FormatUint(r.Result.Metering.ComputationUsed, 10)
+		}
+		rows = append(rows, []string{
+			r.Name,
+			r.Result.TransactionID,
+			strconv.FormatUint(r.Result.BlockHeight, 10),
+			string(r.Result.Status),
+			code,
+			computation,
+			r.Result.Timings.Total.String(),
+			r.Result.Directory,
+		})
+	}
+	return rows
+}
+
+// WriteSummary writes the summary table of the results to w and as a CSV to the batch directory.
+func (b *BatchDebugger) WriteSummary(results []BatchResult, w io.Writer) error {
+	rows := summaryRows(results)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, row := range rows {
+		for i, column := range row {
+			if i > 0 {
+				_, _ = fmt.Fprint(tw, "\t")
+			}
+			_, _ = fmt.Fprint(tw, column)
+		}
+		_, _ = fmt.Fprintln(tw)
+	}
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	return writeCSV(filepath.Join(b.directory, summaryFilename), rows)
+}
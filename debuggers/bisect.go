@@ -0,0 +1,334 @@
+package debuggers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"github.com/onflow/execution-debugger"
+	"github.com/onflow/execution-debugger/registers"
+	"github.com/onflow/flow-dps/api/dps"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// BisectTarget is the transaction or the script that is bisected.
+// Exactly one of Transaction or Script should be set.
+type BisectTarget struct {
+	Transaction *flow.TransactionBody
+	Script      []byte
+	Arguments   [][]byte
+
+	// Impersonated are the accounts that can propose, pay or authorize the transaction without signatures,
+	// Modified is set if the transaction was changed after it was signed, see WithModifiedTransaction.
+	Impersonated []flow.Address
+	Modified     bool
+
+	// CompareEventPayloads also compares the payloads of the events, by default only the event types are compared
+	// because payloads often hold values that change with every block, e.g. IDs, timestamps or balances.
+	CompareEventPayloads bool
+}
+
+// ResolveTransaction sets the transaction of the target, the accounts impersonated by the resolver
+// and whether the resolver modified the transaction.
+func (t *BisectTarget) ResolveTransaction(resolver debugger.TransactionResolver) error {
+	txBody, err := resolver.TransactionBody()
+	if err != nil {
+		return err
+	}
+	if impersonator, ok := resolver.(debugger.Impersonator); ok {
+		t.Impersonated, err = impersonator.ImpersonatedAccounts()
+		if err != nil {
+			return err
+		}
+	}
+	t.Modified, err = transactionModified(resolver, txBody)
+	if err != nil {
+		return err
+	}
+	t.Transaction = txBody
+	return nil
+}
+
+// debuggerOptions returns the RemoteDebugger options of the transaction.
+func (t *BisectTarget) debuggerOptions() []RemoteDebuggerOption {
+	var opts []RemoteDebuggerOption
+	if len(t.Impersonated) > 0 {
+		opts = append(opts, WithImpersonatedAccounts(t.Impersonated...))
+	}
+	if t.Modified {
+		opts = append(opts, WithModifiedTransaction())
+	}
+	return opts
+}
+
+// BisectOutcome is the result of running the target at one height.
+// Two outcomes are the same if they have the same error, return value and event types,
+// and the same event payloads if they are compared.
+type BisectOutcome struct {
+	Height uint64     `json:"height"`
+	Error  string     `json:"error,omitempty"`
+	Value  string     `json:"value,omitempty"`
+	Events []RunEvent `json:"events,omitempty"`
+}
+
+func (o *BisectOutcome) Same(other *BisectOutcome, compareEventPayloads bool) bool {
+	if o.Error != other.Error || o.Value != other.Value || len(o.Events) != len(other.Events) {
+		return false
+	}
+	for i := range o.Events {
+		if o.Events[i].Type != other.Events[i].Type {
+			return false
+		}
+		if compareEventPayloads && o.Events[i].Payload != other.Events[i].Payload {
+			return false
+		}
+	}
+	return true
+}
+
+// RegisterDiff is a register that has a different value at the two boundary heights.
+// Values are hex encoded.
+type RegisterDiff struct {
+	Owner  string `json:"owner"`
+	Key    string `json:"key"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// BisectResult is the outcome of a bisection.
+// If Changed is set, Before is the outcome at the last height that has the same outcome as the start height
+// and After the outcome at the next height, where the outcome is different.
+type BisectResult struct {
+	Changed   bool           `json:"changed"`
+	Before    *BisectOutcome `json:"before"`
+	After     *BisectOutcome `json:"after"`
+	Registers []RegisterDiff `json:"registers,omitempty"`
+}
+
+// Bisector runs a transaction or script over a range of heights
+// to find the first height where its outcome changes.
+// It assumes the outcome changes at most once in the range.
+type Bisector struct {
+	target   BisectTarget
+	debugger *TransactionDebugger
+	log      zerolog.Logger
+}
+
+// NewBisector creates a bisector, the options configure the register and block header sources
+// the same way as for the TransactionDebugger.
+func NewBisector(
+	target BisectTarget,
+	dpsClients []dps.APIClient,
+	chain flow.Chain,
+	logger zerolog.Logger,
+	opts ...TransactionDebuggerOption) *Bisector {
+	return &Bisector{
+		target:   target,
+		debugger: NewTransactionDebugger(nil, dpsClients, chain, logger, opts...),
+		log:      logger,
+	}
+}
+
+// Bisect binary searches for the first height in (startHeight, endHeight]
+// where the outcome differs from the outcome at startHeight.
+func (b *Bisector) Bisect(startHeight, endHeight uint64) (*BisectResult, error) {
+	if startHeight >= endHeight {
+		return nil, fmt.Errorf("start height %d must be lower than end height %d", startHeight, endHeight)
+	}
+
+	before, beforeKeys, err := b.run(startHeight)
+	if err != nil {
+		return nil, err
+	}
+	after, afterKeys, err := b.run(endHeight)
+	if err != nil {
+		return nil, err
+	}
+	if before.Same(after, b.target.CompareEventPayloads) {
+		return &BisectResult{
+			Before: before,
+			After:  after,
+		}, nil
+	}
+
+	for after.Height-before.Height > 1 {
+		mid := before.Height + (after.Height-before.Height)/2
+		outcome, keys, err := b.run(mid)
+		if err != nil {
+			return nil, err
+		}
+
+		b.log.Info().
+			Uint64("height", mid).
+			Bool("same", outcome.Same(before, b.target.CompareEventPayloads)).
+			Msg("Bisect step.")
+
+		if outcome.Same(before, b.target.CompareEventPayloads) {
+			before, beforeKeys = outcome, keys
+		} else {
+			after, afterKeys = outcome, keys
+		}
+	}
+
+	diffs, err := b.diffRegisters(before.Height, after.Height, beforeKeys, afterKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BisectResult{
+		Changed:   true,
+		Before:    before,
+		After:     after,
+		Registers: diffs,
+	}, nil
+}
+
+// run executes the target at the given height and returns the outcome and the registers read.
+func (b *Bisector) run(height uint64) (*BisectOutcome, map[registers.RegisterKey]struct{}, error) {
+	readFunc, closer, err := b.newRegisterReader(height)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closer()
+
+	recorder := &registerKeyRecorder{keys: map[registers.RegisterKey]struct{}{}}
+
+	debuggerOpts, err := b.debugger.remoteDebuggerOptions(height)
+	if err != nil {
+		return nil, nil, err
+	}
+	debuggerOpts = append(debuggerOpts, b.target.debuggerOptions()...)
+
+	view, err := b.debugger.newView(readFunc, recorder)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	directory := filepath.Join(b.debugger.directory, "bisect", strconv.FormatUint(height, 10))
+	dbg := NewRemoteDebugger(view, b.debugger.chain, directory, b.debugger.fvmLog, debuggerOpts...)
+	defer func() {
+		err := dbg.Close()
+		if err != nil {
+			b.log.Warn().
+				Err(err).
+				Msg("Could not close debugger.")
+		}
+	}()
+
+	outcome := &BisectOutcome{Height: height}
+	var events flow.EventsList
+	if b.target.Transaction != nil {
+		tx, _, err := dbg.runTransaction(b.target.Transaction)
+		if err != nil {
+			return nil, nil, err
+		}
+		if tx.Err != nil {
+			outcome.Error = tx.Err.Error()
+		}
+		events = tx.Events
+	} else {
+		script, err := dbg.runScript(b.target.Script, b.target.Arguments)
+		if err != nil {
+			return nil, nil, err
+		}
+		if script.Err != nil {
+			outcome.Error = script.Err.Error()
+		}
+		if script.Value != nil {
+			outcome.Value = script.Value.String()
+		}
+		events = script.Events
+	}
+	if len(events) > 0 {
+		outcome.Events = newRunEvents(events)
+	}
+
+	return outcome, recorder.keys, nil
+}
+
+// diffRegisters compares the registers read at either boundary height.
+func (b *Bisector) diffRegisters(
+	beforeHeight uint64,
+	afterHeight uint64,
+	beforeKeys map[registers.RegisterKey]struct{},
+	afterKeys map[registers.RegisterKey]struct{},
+) ([]RegisterDiff, error) {
+	keys := make([]registers.RegisterKey, 0, len(beforeKeys)+len(afterKeys))
+	for key := range beforeKeys {
+		keys = append(keys, key)
+	}
+	for key := range afterKeys {
+		if _, ok := beforeKeys[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	beforeRead, beforeClose, err := b.newRegisterReader(beforeHeight)
+	if err != nil {
+		return nil, err
+	}
+	defer beforeClose()
+	afterRead, afterClose, err := b.newRegisterReader(afterHeight)
+	if err != nil {
+		return nil, err
+	}
+	defer afterClose()
+
+	var diffs []RegisterDiff
+	for _, key := range keys {
+		before, err := beforeRead(key.Owner, key.Key)
+		if err != nil {
+			return nil, err
+		}
+		after, err := afterRead(key.Owner, key.Key)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(before, after) {
+			continue
+		}
+
+		readable := key.ToReadable()
+		diffs = append(diffs, RegisterDiff{
+			Owner:  readable.Owner,
+			Key:    readable.Key,
+			Before: hex.EncodeToString(before),
+			After:  hex.EncodeToString(after),
+		})
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Owner != diffs[j].Owner {
+			return diffs[i].Owner < diffs[j].Owner
+		}
+		return diffs[i].Key < diffs[j].Key
+	})
+	return diffs, nil
+}
+
+// newRegisterReader creates a register reader for the height backed by the register file cache,
+// so heights visited more than once are only read from the network once.
+func (b *Bisector) newRegisterReader(height uint64) (registers.RegisterGetRegisterFunc, func(), error) {
+	return b.debugger.cachedRegisterReader(height)
+}
+
+// registerKeyRecorder records the keys of the registers that were read.
+type registerKeyRecorder struct {
+	keys map[registers.RegisterKey]struct{}
+}
+
+var _ registers.RegisterGetWrapper = &registerKeyRecorder{}
+
+func (r *registerKeyRecorder) Wrap(inner registers.RegisterGetRegisterFunc) registers.RegisterGetRegisterFunc {
+	return func(owner string, key string) (flow.RegisterValue, error) {
+		val, err := inner(owner, key)
+		if err != nil {
+			return nil, err
+		}
+		r.keys[registers.RegisterKey{Owner: owner, Key: key}] = struct{}{}
+		return val, nil
+	}
+}
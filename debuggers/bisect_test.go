@@ -0,0 +1,96 @@
+package debuggers
+
+import (
+	"github.com/onflow/execution-debugger"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog"
+	"testing"
+)
+
+func TestBisectOutcomeSame(t *testing.T) {
+	deposited := func(payload string) RunEvent {
+		return RunEvent{Type: "A.1654653399040a61.FlowToken.TokensDeposited", Payload: payload}
+	}
+	withdrawn := RunEvent{Type: "A.1654653399040a61.FlowToken.TokensWithdrawn", Payload: "{}"}
+
+	tests := []struct {
+		name            string
+		a, b            BisectOutcome
+		comparePayloads bool
+		same            bool
+	}{
+		{
+			name: "same",
+			a:    BisectOutcome{Value: "1", Events: []RunEvent{deposited("1")}},
+			b:    BisectOutcome{Value: "1", Events: []RunEvent{deposited("1")}},
+			same: true,
+		},
+		{
+			name: "different error",
+			a:    BisectOutcome{Error: "panic"},
+			b:    BisectOutcome{},
+		},
+		{
+			name: "different value",
+			a:    BisectOutcome{Value: "1"},
+			b:    BisectOutcome{Value: "2"},
+		},
+		{
+			name: "different event types",
+			a:    BisectOutcome{Events: []RunEvent{deposited("1")}},
+			b:    BisectOutcome{Events: []RunEvent{withdrawn}},
+		},
+		{
+			name: "different number of events",
+			a:    BisectOutcome{Events: []RunEvent{deposited("1")}},
+			b:    BisectOutcome{Events: []RunEvent{deposited("1"), withdrawn}},
+		},
+		{
+			name: "different payloads are ignored",
+			a:    BisectOutcome{Events: []RunEvent{deposited("1")}},
+			b:    BisectOutcome{Events: []RunEvent{deposited("2")}},
+			same: true,
+		},
+		{
+			name:            "different payloads are compared",
+			a:               BisectOutcome{Events: []RunEvent{deposited("1")}},
+			b:               BisectOutcome{Events: []RunEvent{deposited("2")}},
+			comparePayloads: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.a.Same(&tt.b, tt.comparePayloads) != tt.same {
+				t.Errorf("Same is %v, expected %v", !tt.same, tt.same)
+			}
+		})
+	}
+}
+
+func TestBisectTargetResolveTransaction(t *testing.T) {
+	impersonated := flow.HexToAddress("01")
+	resolver := &debugger.OverriddenTransaction{
+		Resolver: &impersonatingResolver{
+			CustomTransaction: debugger.CustomTransaction{Tx: flow.NewTransactionBody().SetScript([]byte("transaction {}"))},
+			accounts:          []flow.Address{impersonated},
+		},
+		GasLimit: 200,
+	}
+
+	target := &BisectTarget{}
+	err := target.ResolveTransaction(resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target.Transaction == nil || target.Transaction.GasLimit != 200 {
+		t.Errorf("transaction is %v, expected the overridden transaction", target.Transaction)
+	}
+	if !target.Modified {
+		t.Error("overridden transaction is not modified")
+	}
+
+	dbg := NewRemoteDebugger(nil, flow.Emulator.Chain(), t.TempDir(), zerolog.Nop(), target.debuggerOptions()...)
+	if !dbg.impersonated.Contains(impersonated) || len(dbg.impersonated) != 1 || !dbg.modified {
+		t.Errorf("debugger impersonates %v, modified %v", dbg.impersonated.Strings(), dbg.modified)
+	}
+}
@@ -0,0 +1,278 @@
+package debuggers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/execution-debugger"
+	"github.com/onflow/execution-debugger/registers"
+	"github.com/onflow/flow-dps/api/dps"
+	"github.com/onflow/flow-go/fvm/environment"
+	fvmRuntime "github.com/onflow/flow-go/fvm/runtime"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"text/tabwriter"
+)
+
+const contractUsageReportFilename = "usage.json"
+
+// ContractTarget selects the registers of a contract, or all registers of an account if Contract is empty.
+type ContractTarget struct {
+	Address  flow.Address
+	Contract string
+}
+
+// Matches reports whether the readable register key belongs to the target.
+func (t ContractTarget) Matches(key registers.RegisterKey) bool {
+	if key.Owner != t.Address.Hex() {
+		return false
+	}
+	return t.Contract == "" || key.Key == "code."+t.Contract
+}
+
+// MatchesLocation reports whether the Cadence code at the location belongs to the target.
+func (t ContractTarget) MatchesLocation(location common.Location) bool {
+	addressLocation, ok := location.(common.AddressLocation)
+	if !ok || flow.Address(addressLocation.Address) != t.Address {
+		return false
+	}
+	return t.Contract == "" || addressLocation.Name == t.Contract
+}
+
+func (t ContractTarget) String() string {
+	if t.Contract == "" {
+		return t.Address.HexWithPrefix()
+	}
+	return fmt.Sprintf("A.%s.%s", t.Address.Hex(), t.Contract)
+}
+
+// ContractUsage is a transaction that read the target registers or executed the target code.
+// Reads counts the reads of the target registers, each load of the contract code is one read.
+// Calls counts the invocations of the target code from other code, and Computation is the computation
+// spent executing statements of the target code, measured between statements.
+type ContractUsage struct {
+	TransactionID string    `json:"transactionId"`
+	BlockHeight   uint64    `json:"blockHeight"`
+	Status        RunStatus `json:"status"`
+	Reads         int       `json:"reads"`
+	BytesRead     int       `json:"bytesRead"`
+	Calls         int       `json:"calls"`
+	Computation   uint64    `json:"computation"`
+	// TransactionComputation is the computation used by the whole transaction.
+	TransactionComputation uint64 `json:"transactionComputation"`
+	Directory              string `json:"directory"`
+}
+
+// ContractUsageReport lists the transactions of a height range that used the target, in execution order.
+type ContractUsageReport struct {
+	Target       string          `json:"target"`
+	StartHeight  uint64          `json:"startHeight"`
+	EndHeight    uint64          `json:"endHeight"`
+	Transactions int             `json:"transactions"`
+	Reads        int             `json:"reads"`
+	Calls        int             `json:"calls"`
+	Computation  uint64          `json:"computation"`
+	Usages       []ContractUsage `json:"usages"`
+	// Errors are the transactions that could not be replayed, by transaction ID.
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// contractUsageMeter counts the calls of the target code and the computation spent executing it,
+// using the statement hook of the Cadence runtime.
+type contractUsageMeter struct {
+	target ContractTarget
+
+	mu          sync.Mutex
+	calls       int
+	computation uint64
+
+	// the computation used, call stack depth and target match at the previous statement
+	lastComputation uint64
+	lastDepth       int
+	lastInTarget    bool
+}
+
+func newContractUsageMeter(target ContractTarget) *contractUsageMeter {
+	return &contractUsageMeter{
+		target:    target,
+		lastDepth: -1,
+	}
+}
+
+// OnStatement attributes the computation used since the previous statement to the code of the previous statement,
+// and counts a call when the call stack grew into the target code from other code.
+func (m *contractUsageMeter) OnStatement(fvmEnv fvmRuntime.Environment, inter *interpreter.Interpreter, _ ast.Statement) {
+	env, ok := fvmEnv.(environment.Environment)
+	if !ok {
+		return
+	}
+
+	m.record(env.ComputationUsed(), len(inter.CallStack()), inter.Location)
+}
+
+// record accounts a statement executed at the call stack depth in the code at the location.
+func (m *contractUsageMeter) record(computation uint64, depth int, location common.Location) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.lastInTarget && computation > m.lastComputation {
+		m.computation += computation - m.lastComputation
+	}
+
+	inTarget := m.target.MatchesLocation(location)
+	if inTarget && !m.lastInTarget && depth > m.lastDepth {
+		m.calls++
+	}
+
+	m.lastComputation = computation
+	m.lastDepth = depth
+	m.lastInTarget = inTarget
+}
+
+// Usage returns the calls of the target code and the computation spent executing it.
+func (m *contractUsageMeter) Usage() (calls int, computation uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.calls, m.computation
+}
+
+// ContractUsageScanner replays the transactions of a height range
+// and finds the ones that read the registers of a contract or an account.
+type ContractUsageScanner struct {
+	client dps.APIClient
+	batch  *BatchDebugger
+	target ContractTarget
+	log    zerolog.Logger
+}
+
+func NewContractUsageScanner(
+	client dps.APIClient,
+	batch *BatchDebugger,
+	target ContractTarget,
+	logger zerolog.Logger) *ContractUsageScanner {
+	return &ContractUsageScanner{
+		client: client,
+		batch:  batch,
+		target: target,
+		log:    logger,
+	}
+}
+
+// Scan replays the transactions of the blocks from startHeight to endHeight, both included.
+func (s *ContractUsageScanner) Scan(ctx context.Context, startHeight, endHeight uint64) (*ContractUsageReport, error) {
+	report := &ContractUsageReport{
+		Target:      s.target.String(),
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+	}
+
+	var entries []BatchEntry
+	var counters []*registers.RegisterReadCounter
+	var meters []*contractUsageMeter
+	var err error
+	report.Transactions, err = scanHeights(ctx, s.client, startHeight, endHeight, func(height uint64, network *debugger.NetworkTransactions) error {
+		counter := registers.NewRegisterReadCounter(s.target.Matches)
+		counters = append(counters, counter)
+		meter := newContractUsageMeter(s.target)
+		meters = append(meters, meter)
+		entries = append(entries, BatchEntry{
+			Name:     network.ID.String(),
+			Resolver: network,
+			Options: []TransactionDebuggerOption{
+				WithRegisterGetWrappers(counter),
+				WithRemoteDebuggerOptions(WithStatementHooks(meter.OnStatement)),
+			},
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.log.Info().
+		Int("transactions", report.Transactions).
+		Str("target", report.Target).
+		Msg("Replaying transactions.")
+
+	results := s.batch.Run(ctx, entries)
+	for i, r := range results {
+		if r.Result == nil || r.Result.Status == RunStatusError {
+			if report.Errors == nil {
+				report.Errors = make(map[string]string)
+			}
+			report.Errors[r.Name] = r.Error
+			if r.Result != nil && r.Result.Error != nil {
+				report.Errors[r.Name] = r.Result.Error.Message
+			}
+		}
+		if r.Result == nil {
+			continue
+		}
+
+		reads, bytesRead := counters[i].Reads()
+		calls, computation := meters[i].Usage()
+		if reads == 0 && calls == 0 {
+			continue
+		}
+
+		usage := ContractUsage{
+			TransactionID: r.Result.TransactionID,
+			BlockHeight:   r.Result.BlockHeight,
+			Status:        r.Result.Status,
+			Reads:         reads,
+			BytesRead:     bytesRead,
+			Calls:         calls,
+			Computation:   computation,
+			Directory:     r.Result.Directory,
+		}
+		if r.Result.Metering != nil {
+			usage.TransactionComputation = r.Result.Metering.ComputationUsed
+		}
+		report.Usages = append(report.Usages, usage)
+		report.Reads += usage.Reads
+		report.Calls += usage.Calls
+		report.Computation += usage.Computation
+	}
+
+	return report, nil
+}
+
+// WriteReport writes the usage table to w and the full report as JSON to the batch directory.
+func (s *ContractUsageScanner) WriteReport(report *ContractUsageReport, w io.Writer) error {
+	_, _ = fmt.Fprintf(w, "%d of %d transactions used %s between heights %d and %d\n",
+		len(report.Usages), report.Transactions, report.Target, report.StartHeight, report.EndHeight)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "Transaction\tHeight\tStatus\tReads\tCalls\tComputation\tTransaction computation")
+	for _, usage := range report.Usages {
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\t%d\t%d\t%d\t%d\n",
+			usage.TransactionID, usage.BlockHeight, usage.Status,
+			usage.Reads, usage.Calls, usage.Computation, usage.TransactionComputation)
+	}
+	_, _ = fmt.Fprintf(tw, "Total\t\t\t%d\t%d\t%d\t\n", report.Reads, report.Calls, report.Computation)
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+	if len(report.Errors) > 0 {
+		_, _ = fmt.Fprintf(w, "%d transactions could not be replayed\n", len(report.Errors))
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	filename := filepath.Join(s.batch.directory, contractUsageReportFilename)
+	err = os.MkdirAll(filepath.Dir(filename), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
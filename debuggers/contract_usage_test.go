@@ -0,0 +1,103 @@
+package debuggers
+
+import (
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/flow-go/model/flow"
+	"testing"
+)
+
+func TestContractUsageMeter(t *testing.T) {
+	address := flow.HexToAddress("0x1")
+	transaction := common.TransactionLocation{0x1}
+	token := common.AddressLocation{Address: common.Address(address), Name: "Token"}
+	market := common.AddressLocation{Address: common.Address(address), Name: "Market"}
+
+	type statement struct {
+		computation uint64
+		depth       int
+		location    common.Location
+	}
+	tests := []struct {
+		name        string
+		target      ContractTarget
+		statements  []statement
+		calls       int
+		computation uint64
+	}{
+		{
+			name:   "not called",
+			target: ContractTarget{Address: address, Contract: "Token"},
+			statements: []statement{
+				{computation: 0, depth: 0, location: transaction},
+				{computation: 5, depth: 1, location: market},
+				{computation: 9, depth: 0, location: transaction},
+			},
+		},
+		{
+			name:   "called from the transaction",
+			target: ContractTarget{Address: address, Contract: "Token"},
+			statements: []statement{
+				{computation: 0, depth: 0, location: transaction},
+				{computation: 2, depth: 1, location: token},
+				{computation: 5, depth: 1, location: token},
+				{computation: 9, depth: 0, location: transaction},
+				{computation: 10, depth: 1, location: token},
+				{computation: 12, depth: 0, location: transaction},
+			},
+			calls:       2,
+			computation: 9,
+		},
+		{
+			name:   "calls within the target are not counted",
+			target: ContractTarget{Address: address, Contract: "Token"},
+			statements: []statement{
+				{computation: 0, depth: 0, location: transaction},
+				{computation: 1, depth: 1, location: token},
+				{computation: 3, depth: 2, location: token},
+				{computation: 4, depth: 1, location: token},
+				{computation: 6, depth: 0, location: transaction},
+			},
+			calls:       1,
+			computation: 5,
+		},
+		{
+			name:   "computation of other contracts called by the target is not attributed",
+			target: ContractTarget{Address: address, Contract: "Token"},
+			statements: []statement{
+				{computation: 0, depth: 0, location: transaction},
+				{computation: 1, depth: 1, location: token},
+				{computation: 2, depth: 2, location: market},
+				{computation: 10, depth: 1, location: token},
+				{computation: 12, depth: 0, location: transaction},
+			},
+			calls:       1,
+			computation: 3,
+		},
+		{
+			name:   "account target",
+			target: ContractTarget{Address: address},
+			statements: []statement{
+				{computation: 0, depth: 0, location: transaction},
+				{computation: 1, depth: 1, location: token},
+				{computation: 2, depth: 2, location: market},
+				{computation: 10, depth: 1, location: token},
+				{computation: 12, depth: 0, location: transaction},
+			},
+			calls:       1,
+			computation: 11,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meter := newContractUsageMeter(tt.target)
+			for _, s := range tt.statements {
+				meter.record(s.computation, s.depth, s.location)
+			}
+			calls, computation := meter.Usage()
+			if calls != tt.calls || computation != tt.computation {
+				t.Errorf("%d calls and %d computation, expected %d calls and %d computation",
+					calls, computation, tt.calls, tt.computation)
+			}
+		})
+	}
+}
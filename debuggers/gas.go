@@ -0,0 +1,193 @@
+package debuggers
+
+import (
+	"github.com/onflow/execution-debugger"
+	"github.com/onflow/execution-debugger/registers"
+	"github.com/onflow/flow-dps/api/dps"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog"
+	"path/filepath"
+	"strconv"
+)
+
+// GasLimitSearchResult is the outcome of a gas limit search.
+// The metering is the one of the run at the minimum limit.
+type GasLimitSearchResult struct {
+	// Succeeded is false if the transaction fails even with the maximum limit,
+	// Error is the error of the run with the maximum limit.
+	Succeeded     bool   `json:"succeeded"`
+	Error         string `json:"error,omitempty"`
+	OriginalLimit uint64 `json:"originalLimit"`
+	MinimumLimit  uint64 `json:"minimumLimit"`
+	Runs          int    `json:"runs"`
+
+	Metering *Metering `json:"metering"`
+}
+
+// GasLimitSearch re-runs a transaction with different computation limits
+// to find the minimum limit it succeeds with.
+type GasLimitSearch struct {
+	debugger *TransactionDebugger
+	maxLimit uint64
+	log      zerolog.Logger
+}
+
+// NewGasLimitSearch creates a gas limit search of the transaction with limits up to maxLimit,
+// the options configure the register and block header sources the same way as for the TransactionDebugger.
+func NewGasLimitSearch(
+	txResolver debugger.TransactionResolver,
+	dpsClients []dps.APIClient,
+	chain flow.Chain,
+	logger zerolog.Logger,
+	maxLimit uint64,
+	opts ...TransactionDebuggerOption) *GasLimitSearch {
+	return &GasLimitSearch{
+		debugger: NewTransactionDebugger(txResolver, dpsClients, chain, logger, opts...),
+		maxLimit: maxLimit,
+		log:      logger,
+	}
+}
+
+// gasLimitRun is the outcome of running the transaction with one limit.
+type gasLimitRun struct {
+	limit    uint64
+	err      error
+	metering *Metering
+}
+
+func (s *GasLimitSearch) Search() (*GasLimitSearchResult, error) {
+	blockHeight, err := s.debugger.txResolver.BlockHeight()
+	if err != nil {
+		return nil, err
+	}
+	txBody, err := s.debugger.txResolver.TransactionBody()
+	if err != nil {
+		return nil, err
+	}
+	err = s.debugger.defaultDirectory(txBody)
+	if err != nil {
+		return nil, err
+	}
+
+	readFunc, closeCache, err := s.debugger.cachedRegisterReader(blockHeight)
+	if err != nil {
+		return nil, err
+	}
+	defer closeCache()
+
+	debuggerOpts, err := s.debugger.remoteDebuggerOptions(blockHeight)
+	if err != nil {
+		return nil, err
+	}
+	modified, err := transactionModified(s.debugger.txResolver, txBody)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &GasLimitSearchResult{
+		OriginalLimit: txBody.GasLimit,
+	}
+	run := func(limit uint64) (*gasLimitRun, error) {
+		result.Runs++
+		opts := debuggerOpts
+		// the signatures do not cover the changed gas limit
+		if modified || limit != txBody.GasLimit {
+			opts = append(append([]RemoteDebuggerOption{}, debuggerOpts...), WithModifiedTransaction())
+		}
+		return s.run(txBody, limit, readFunc, opts)
+	}
+
+	best, err := run(s.maxLimit)
+	if err != nil {
+		return nil, err
+	}
+	if best.err != nil {
+		result.Error = best.err.Error()
+		result.MinimumLimit = s.maxLimit
+		result.Metering = best.metering
+		return result, nil
+	}
+	result.Succeeded = true
+
+	// the limit fails below low and succeeds at high
+	low, high := uint64(0), s.maxLimit
+	// the computation used with a high enough limit is the most likely minimum, try it first
+	if used := best.metering.ComputationUsed; used > low && used < high {
+		probe, err := run(used)
+		if err != nil {
+			return nil, err
+		}
+		if probe.err == nil {
+			best, high = probe, used
+		} else {
+			low = used
+		}
+	}
+
+	for high-low > 1 {
+		mid := low + (high-low)/2
+		probe, err := run(mid)
+		if err != nil {
+			return nil, err
+		}
+
+		s.log.Info().
+			Uint64("limit", mid).
+			Bool("succeeded", probe.err == nil).
+			Msg("Gas limit search step.")
+
+		if probe.err == nil {
+			best, high = probe, mid
+		} else {
+			low = mid
+		}
+	}
+
+	result.MinimumLimit = high
+	result.Metering = best.metering
+	return result, nil
+}
+
+// run executes the transaction with the given computation limit on a fresh view.
+func (s *GasLimitSearch) run(
+	txBody *flow.TransactionBody,
+	limit uint64,
+	readFunc registers.RegisterGetRegisterFunc,
+	debuggerOpts []RemoteDebuggerOption,
+) (*gasLimitRun, error) {
+	limited := *txBody
+	limited.GasLimit = limit
+
+	view, err := s.debugger.newView(readFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	directory := filepath.Join(s.debugger.directory, "gas", strconv.FormatUint(limit, 10))
+	dbg := NewRemoteDebugger(view, s.debugger.chain, directory, s.debugger.fvmLog, debuggerOpts...)
+	defer func() {
+		err := dbg.Close()
+		if err != nil {
+			s.log.Warn().
+				Err(err).
+				Msg("Could not close debugger.")
+		}
+	}()
+
+	tx, metering, err := dbg.runTransaction(&limited)
+	if err != nil {
+		return nil, err
+	}
+	if metering == nil {
+		metering = &Metering{}
+	}
+
+	r := &gasLimitRun{
+		limit:    limit,
+		metering: metering,
+	}
+	if tx.Err != nil {
+		r.err = tx.Err
+	}
+	return r, nil
+}
@@ -0,0 +1,214 @@
+package debuggers
+
+import (
+	"fmt"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/crypto"
+	"github.com/onflow/flow-go/fvm/environment"
+	"github.com/onflow/flow-go/fvm/errors"
+	"github.com/onflow/flow-go/fvm/programs"
+	"github.com/onflow/flow-go/fvm/state"
+	"github.com/onflow/flow-go/model/flow"
+	"sort"
+)
+
+// ImpersonatedAccounts is a set of accounts that can act as proposer, payer or authorizer
+// of a simulated transaction without signing it.
+type ImpersonatedAccounts map[flow.Address]struct{}
+
+func NewImpersonatedAccounts(addresses ...flow.Address) ImpersonatedAccounts {
+	accounts := make(ImpersonatedAccounts, len(addresses))
+	for _, address := range addresses {
+		accounts[address] = struct{}{}
+	}
+	return accounts
+}
+
+func (a ImpersonatedAccounts) Contains(address flow.Address) bool {
+	_, ok := a[address]
+	return ok
+}
+
+// Strings returns the hex encoded addresses, sorted.
+func (a ImpersonatedAccounts) Strings() []string {
+	addresses := make([]string, 0, len(a))
+	for address := range a {
+		addresses = append(addresses, address.HexWithPrefix())
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// ImpersonationVerifier verifies transaction signatures the same way as fvm.TransactionVerifier,
+// except that signatures are not required from impersonated accounts.
+type ImpersonationVerifier struct {
+	impersonated       ImpersonatedAccounts
+	keyWeightThreshold int
+}
+
+var _ fvm.TransactionProcessor = &ImpersonationVerifier{}
+
+func NewImpersonationVerifier(impersonated ImpersonatedAccounts) *ImpersonationVerifier {
+	return &ImpersonationVerifier{
+		impersonated:       impersonated,
+		keyWeightThreshold: fvm.AccountKeyWeightThreshold,
+	}
+}
+
+func (v *ImpersonationVerifier) Process(
+	_ fvm.Context,
+	proc *fvm.TransactionProcedure,
+	txnState *state.TransactionState,
+	_ *programs.TransactionPrograms,
+) error {
+	err := v.verifyTransaction(proc.Transaction, txnState)
+	if err != nil {
+		return fmt.Errorf("transaction verification failed: %w", err)
+	}
+	return nil
+}
+
+func (v *ImpersonationVerifier) verifyTransaction(tx *flow.TransactionBody, txnState *state.TransactionState) error {
+	if tx.Payer == flow.EmptyAddress {
+		return errors.NewInvalidAddressErrorf(tx.Payer, "payer address is invalid")
+	}
+
+	accounts := environment.NewAccounts(txnState)
+
+	payloadWeights, proposalKeyVerifiedInPayload, err := v.verifySignatures(
+		txnState,
+		accounts,
+		tx.PayloadSignatures,
+		tx.PayloadMessage(),
+		tx.ProposalKey,
+		errors.NewInvalidPayloadSignatureError,
+	)
+	if err != nil {
+		return errors.NewInvalidProposalSignatureError(tx.ProposalKey, err)
+	}
+
+	envelopeWeights, proposalKeyVerifiedInEnvelope, err := v.verifySignatures(
+		txnState,
+		accounts,
+		tx.EnvelopeSignatures,
+		tx.EnvelopeMessage(),
+		tx.ProposalKey,
+		errors.NewInvalidEnvelopeSignatureError,
+	)
+	if err != nil {
+		return errors.NewInvalidProposalSignatureError(tx.ProposalKey, err)
+	}
+
+	proposalKeyVerified := proposalKeyVerifiedInPayload || proposalKeyVerifiedInEnvelope
+	if !proposalKeyVerified && !v.impersonated.Contains(tx.ProposalKey.Address) {
+		err := fmt.Errorf("either the payload or the envelope should provide proposal signatures")
+		return errors.NewInvalidProposalSignatureError(tx.ProposalKey, err)
+	}
+
+	for _, addr := range tx.Authorizers {
+		// the payer only signs the envelope
+		if addr == tx.Payer || v.impersonated.Contains(addr) {
+			continue
+		}
+		if payloadWeights[addr] < v.keyWeightThreshold {
+			return errors.NewAccountAuthorizationErrorf(
+				addr,
+				"authorizer account does not have sufficient signatures (%d < %d)",
+				payloadWeights[addr],
+				v.keyWeightThreshold)
+		}
+	}
+
+	if !v.impersonated.Contains(tx.Payer) && envelopeWeights[tx.Payer] < v.keyWeightThreshold {
+		return errors.NewAccountAuthorizationErrorf(
+			tx.Payer,
+			"payer account does not have sufficient signatures (%d < %d)",
+			envelopeWeights[tx.Payer],
+			v.keyWeightThreshold)
+	}
+
+	return nil
+}
+
+// verifySignatures verifies the signatures of accounts that are not impersonated
+// and returns the key weight provided per account.
+func (v *ImpersonationVerifier) verifySignatures(
+	txnState *state.TransactionState,
+	accounts environment.Accounts,
+	signatures []flow.TransactionSignature,
+	message []byte,
+	proposalKey flow.ProposalKey,
+	errorBuilder func(flow.TransactionSignature, error) errors.CodedError,
+) (
+	weights map[flow.Address]int,
+	proposalKeyVerified bool,
+	err error,
+) {
+	weights = make(map[flow.Address]int)
+
+	for _, txSig := range signatures {
+		if v.impersonated.Contains(txSig.Address) {
+			continue
+		}
+
+		var accountKey flow.AccountPublicKey
+		txnState.RunWithAllLimitsDisabled(func() {
+			accountKey, err = accounts.GetPublicKey(txSig.Address, txSig.KeyIndex)
+		})
+		if err != nil {
+			return nil, false, errorBuilder(txSig, err)
+		}
+		if accountKey.Revoked {
+			return nil, false, errorBuilder(txSig, fmt.Errorf("account key has been revoked"))
+		}
+
+		valid, err := crypto.VerifySignatureFromTransaction(
+			txSig.Signature,
+			message,
+			accountKey.PublicKey,
+			accountKey.HashAlgo,
+		)
+		if err != nil {
+			return nil, false, errorBuilder(txSig, err)
+		}
+		if !valid {
+			return nil, false, errorBuilder(txSig, fmt.Errorf("signature is not valid"))
+		}
+
+		if txSig.Address == proposalKey.Address && txSig.KeyIndex == proposalKey.KeyIndex {
+			proposalKeyVerified = true
+		}
+		weights[txSig.Address] += accountKey.Weight
+	}
+
+	return weights, proposalKeyVerified, nil
+}
+
+// ImpersonationSequenceNumberChecker checks and increments the proposal key sequence number,
+// unless the proposer is impersonated. The incremented sequence number is written to the state,
+// so it is part of the registers written by the run.
+type ImpersonationSequenceNumberChecker struct {
+	impersonated ImpersonatedAccounts
+	checker      *fvm.TransactionSequenceNumberChecker
+}
+
+var _ fvm.TransactionProcessor = &ImpersonationSequenceNumberChecker{}
+
+func NewImpersonationSequenceNumberChecker(impersonated ImpersonatedAccounts) *ImpersonationSequenceNumberChecker {
+	return &ImpersonationSequenceNumberChecker{
+		impersonated: impersonated,
+		checker:      fvm.NewTransactionSequenceNumberChecker(),
+	}
+}
+
+func (c *ImpersonationSequenceNumberChecker) Process(
+	ctx fvm.Context,
+	proc *fvm.TransactionProcedure,
+	txnState *state.TransactionState,
+	txnPrograms *programs.TransactionPrograms,
+) error {
+	if c.impersonated.Contains(proc.Transaction.ProposalKey.Address) {
+		return nil
+	}
+	return c.checker.Process(ctx, proc, txnState, txnPrograms)
+}
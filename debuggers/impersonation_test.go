@@ -0,0 +1,52 @@
+package debuggers
+
+import (
+	"fmt"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog"
+	"testing"
+)
+
+func TestImpersonationProcessors(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []RemoteDebuggerOption
+		processors []string
+	}{
+		{name: "no impersonation", processors: []string{"*debuggers.meteringInvoker"}},
+		{
+			name:       "modified without impersonation",
+			opts:       []RemoteDebuggerOption{WithModifiedTransaction()},
+			processors: []string{"*debuggers.meteringInvoker"},
+		},
+		{
+			name: "impersonation",
+			opts: []RemoteDebuggerOption{WithImpersonatedAccounts(flow.HexToAddress("01"))},
+			processors: []string{
+				"*debuggers.ImpersonationVerifier",
+				"*debuggers.ImpersonationSequenceNumberChecker",
+				"*debuggers.meteringInvoker",
+			},
+		},
+		{
+			name: "impersonation of a modified transaction",
+			opts: []RemoteDebuggerOption{WithImpersonatedAccounts(flow.HexToAddress("01")), WithModifiedTransaction()},
+			processors: []string{
+				"*debuggers.ImpersonationSequenceNumberChecker",
+				"*debuggers.meteringInvoker",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewRemoteDebugger(nil, flow.Emulator.Chain(), t.TempDir(), zerolog.Nop(), tt.opts...)
+			processors := make([]string, 0, len(d.ctx.TransactionProcessors))
+			for _, processor := range d.ctx.TransactionProcessors {
+				processors = append(processors, fmt.Sprintf("%T", processor))
+			}
+			if fmt.Sprint(processors) != fmt.Sprint(tt.processors) {
+				t.Errorf("processors are %v, expected %v", processors, tt.processors)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package debuggers
+
+import (
+	"encoding/json"
+	"github.com/onflow/execution-debugger"
+	"os"
+	"path/filepath"
+)
+
+const manifestFilename = "manifest.json"
+
+// RunManifest describes what was executed in a debugger run.
+// It is written to the run directory next to the other run artifacts.
+type RunManifest struct {
+	// TransactionID is the ID of the transaction on the network.
+	TransactionID string `json:"transactionId"`
+	// ExecutedTransactionID is the ID of the executed transaction, if it was changed before the execution.
+	ExecutedTransactionID string                       `json:"executedTransactionId,omitempty"`
+	BlockHeight           uint64                       `json:"blockHeight"`
+	Chain                 string                       `json:"chain"`
+	Changes               []debugger.TransactionChange `json:"changes,omitempty"`
+	// Patches are the state patches applied before the execution.
+	Patches []debugger.TransactionChange `json:"patches,omitempty"`
+	// Impersonated are the accounts that could propose, pay or authorize without signatures.
+	// If accounts are impersonated, the signatures and the proposal key sequence numbers of all the other accounts
+	// are checked, and the sequence number of a checked proposal key is incremented in the state, as on the network.
+	Impersonated []string `json:"impersonated,omitempty"`
+}
+
+// Write writes the manifest to the run directory.
+func (m *RunManifest) Write(directory string) error {
+	filename := filepath.Join(directory, manifestFilename)
+	err := os.MkdirAll(filepath.Dir(filename), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
@@ -0,0 +1,178 @@
+package debuggers
+
+import (
+	"encoding/csv"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/programs"
+	"github.com/onflow/flow-go/fvm/state"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+const (
+	computationFilename = "computation_intensities.csv"
+	memoryFilename      = "memory_intensities.csv"
+	totalsFilename      = "metering_totals.csv"
+)
+
+// Metering is the computation and memory used by a transaction, as metered by the FVM.
+type Metering struct {
+	ComputationUsed  uint64 `json:"computationUsed"`
+	ComputationLimit uint64 `json:"computationLimit"`
+	MemoryEstimate   uint64 `json:"memoryEstimate"`
+	MemoryLimit      uint64 `json:"memoryLimit"`
+	InteractionUsed  uint64 `json:"interactionUsed"`
+
+	// ComputationIntensities and MemoryIntensities are keyed by computation and memory kind.
+	ComputationIntensities map[uint64]uint64 `json:"computationIntensities"`
+	MemoryIntensities      map[uint64]uint64 `json:"memoryIntensities"`
+}
+
+// meteringInvoker decorates the transaction invoker and records the metering of the transaction state
+// once the transaction has been invoked, including when it failed.
+type meteringInvoker struct {
+	invoker fvm.TransactionProcessor
+	last    *Metering
+}
+
+var _ fvm.TransactionProcessor = &meteringInvoker{}
+
+func newMeteringInvoker() *meteringInvoker {
+	return &meteringInvoker{
+		invoker: fvm.NewTransactionInvoker(),
+	}
+}
+
+func (i *meteringInvoker) Process(
+	ctx fvm.Context,
+	proc *fvm.TransactionProcedure,
+	txnState *state.TransactionState,
+	txnPrograms *programs.TransactionPrograms,
+) error {
+	err := i.invoker.Process(ctx, proc, txnState, txnPrograms)
+
+	metering := &Metering{
+		ComputationUsed:        uint64(txnState.TotalComputationUsed()),
+		ComputationLimit:       uint64(txnState.TotalComputationLimit()),
+		MemoryEstimate:         txnState.TotalMemoryEstimate(),
+		MemoryLimit:            proc.MemoryLimit(ctx),
+		InteractionUsed:        txnState.InteractionUsed(),
+		ComputationIntensities: map[uint64]uint64{},
+		MemoryIntensities:      map[uint64]uint64{},
+	}
+	for kind, intensity := range txnState.ComputationIntensities() {
+		metering.ComputationIntensities[uint64(kind)] = uint64(intensity)
+	}
+	for kind, intensity := range txnState.MemoryIntensities() {
+		metering.MemoryIntensities[uint64(kind)] = uint64(intensity)
+	}
+	i.last = metering
+
+	return err
+}
+
+// WriteCSV writes the computation and memory intensities, sorted from the highest,
+// and the totals with their limits to the run directory.
+func (m *Metering) WriteCSV(directory string) error {
+	err := writeIntensitiesCSV(
+		filepath.Join(directory, computationFilename),
+		"*Computation Kind",
+		m.ComputationIntensities,
+		ComputationKindName,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = writeIntensitiesCSV(
+		filepath.Join(directory, memoryFilename),
+		"Memory Kind",
+		m.MemoryIntensities,
+		MemoryKindName,
+	)
+	if err != nil {
+		return err
+	}
+
+	return writeCSV(filepath.Join(directory, totalsFilename), [][]string{
+		{"Total", "Used", "Limit", "% of Limit"},
+		totalRow("Computation", m.ComputationUsed, m.ComputationLimit),
+		totalRow("Memory", m.MemoryEstimate, m.MemoryLimit),
+		totalRow("Interaction", m.InteractionUsed, 0),
+	})
+}
+
+// SortedKinds returns the kinds of the intensities, from the highest intensity to the lowest.
+func SortedKinds(intensities map[uint64]uint64) []uint64 {
+	kinds := make([]uint64, 0, len(intensities))
+	for kind := range intensities {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool {
+		if intensities[kinds[i]] != intensities[kinds[j]] {
+			return intensities[kinds[i]] > intensities[kinds[j]]
+		}
+		return kinds[i] < kinds[j]
+	})
+	return kinds
+}
+
+// MemoryKindName returns the name of the memory kind, or its number if it is not known.
+func MemoryKindName(kind uint64) string {
+	if kind >= uint64(common.MemoryKindLast) {
+		return strconv.FormatUint(kind, 10)
+	}
+	return common.MemoryKind(kind).String()
+}
+
+// totalRow formats a total, the limit and percentage are left empty if there is no limit.
+func totalRow(name string, used uint64, limit uint64) []string {
+	if limit == 0 || limit == math.MaxUint64 {
+		return []string{name, strconv.FormatUint(used, 10), "", ""}
+	}
+	return []string{
+		name,
+		strconv.FormatUint(used, 10),
+		strconv.FormatUint(limit, 10),
+		strconv.FormatFloat(float64(used)/float64(limit)*100, 'f', 2, 64),
+	}
+}
+
+// writeIntensitiesCSV writes the intensities, sorted from the highest. Intensities are not weighted,
+// so they can not be compared between kinds or to the limits, the weighted totals are in the totals file.
+func writeIntensitiesCSV(filename string, header string, intensities map[uint64]uint64, name func(uint64) string) error {
+	rows := [][]string{{header, "Intensity"}}
+	for _, kind := range SortedKinds(intensities) {
+		rows = append(rows, []string{
+			name(kind),
+			strconv.FormatUint(intensities[kind], 10),
+		})
+	}
+
+	return writeCSV(filename, rows)
+}
+
+func writeCSV(filename string, rows [][]string) error {
+	err := os.MkdirAll(filepath.Dir(filename), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	csvFile, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = csvFile.Close()
+	}()
+
+	writer := csv.NewWriter(csvFile)
+	err = writer.WriteAll(rows)
+	if err != nil {
+		return err
+	}
+	return csvFile.Close()
+}
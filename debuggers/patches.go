@@ -0,0 +1,120 @@
+package debuggers
+
+import (
+	"fmt"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/execution-debugger"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog"
+)
+
+// setFlowBalanceTransaction mints or burns FLOW until the vault of the first authorizer has the given balance,
+// the second authorizer is the service account, which holds the FLOW administrator.
+const setFlowBalanceTransaction = `
+import FungibleToken from 0x%s
+import FlowToken from 0x%s
+
+transaction(balance: UFix64) {
+	prepare(account: AuthAccount, service: AuthAccount) {
+		let vault = account.borrow<&FlowToken.Vault>(from: /storage/flowTokenVault)
+			?? panic("account has no FLOW vault")
+		if vault.balance > balance {
+			destroy vault.withdraw(amount: vault.balance - balance)
+		} else if vault.balance < balance {
+			let admin = service.borrow<&FlowToken.Administrator>(from: /storage/flowTokenAdmin)
+				?? panic("service account has no FLOW administrator")
+			let minter <- admin.createNewMinter(allowedAmount: balance - vault.balance)
+			vault.deposit(from: <- minter.mintTokens(amount: balance - vault.balance))
+			destroy minter
+		}
+	}
+}
+`
+
+// saveValueTransaction replaces the value stored at the path of the authorizer.
+const saveValueTransaction = `
+transaction(path: StoragePath, value: AnyStruct) {
+	prepare(account: AuthAccount) {
+		if let type = account.type(at: path) {
+			if type.isSubtype(of: Type<@AnyResource>()) {
+				destroy account.load<@AnyResource>(from: path)
+			} else {
+				account.load<AnyStruct>(from: path)
+			}
+		}
+		account.save(value, to: path)
+	}
+}
+`
+
+// applyStatePatches applies the patches to the view. Registers are set directly,
+// the other patches are applied by transactions executed without signature checks.
+func applyStatePatches(
+	view *debugger.RemoteView,
+	patches *debugger.StatePatches,
+	chain flow.Chain,
+	directory string,
+	logger zerolog.Logger) error {
+	for _, patch := range patches.Registers {
+		err := view.Set(patch.Key.Owner, patch.Key.Key, patch.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(patches.FlowBalances) == 0 && len(patches.Storage) == 0 {
+		return nil
+	}
+
+	// the debugger is not closed, so the patches do not write a profile to the run directory
+	dbg := NewRemoteDebugger(view, chain, directory, logger)
+
+	for _, patch := range patches.FlowBalances {
+		balance, err := jsoncdc.Encode(patch.Balance)
+		if err != nil {
+			return err
+		}
+		script := fmt.Sprintf(setFlowBalanceTransaction, fvm.FungibleTokenAddress(chain).Hex(), fvm.FlowTokenAddress(chain).Hex())
+		txBody := flow.NewTransactionBody().
+			SetScript([]byte(script)).
+			AddArgument(balance).
+			AddAuthorizer(patch.Address).
+			AddAuthorizer(chain.ServiceAddress()).
+			SetPayer(chain.ServiceAddress()).
+			SetProposalKey(chain.ServiceAddress(), 0, 0).
+			SetGasLimit(debugger.DefaultGasLimit)
+		err = runPatchTransaction(dbg, txBody)
+		if err != nil {
+			return fmt.Errorf("could not set FLOW balance of %s: %w", patch.Address.HexWithPrefix(), err)
+		}
+	}
+
+	for _, patch := range patches.Storage {
+		path, err := jsoncdc.Encode(patch.Path)
+		if err != nil {
+			return err
+		}
+		txBody := flow.NewTransactionBody().
+			SetScript([]byte(saveValueTransaction)).
+			AddArgument(path).
+			AddArgument(patch.Value).
+			AddAuthorizer(patch.Address).
+			SetPayer(patch.Address).
+			SetProposalKey(patch.Address, 0, 0).
+			SetGasLimit(debugger.DefaultGasLimit)
+		err = runPatchTransaction(dbg, txBody)
+		if err != nil {
+			return fmt.Errorf("could not save value at %s%s: %w", patch.Address.HexWithPrefix(), patch.Path, err)
+		}
+	}
+	return nil
+}
+
+func runPatchTransaction(dbg *RemoteDebugger, txBody *flow.TransactionBody) error {
+	tx, _, err := dbg.runTransaction(txBody)
+	if err != nil {
+		return err
+	}
+	return tx.Err
+}
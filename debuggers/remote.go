@@ -22,62 +22,175 @@ type RemoteDebugger struct {
 	view state.View
 
 	profileBuilder *ProfileBuilder
+	statementHooks []StatementHook
+	impersonated   ImpersonatedAccounts
+	modified       bool
+	invoker        *meteringInvoker
+}
+
+// StatementHook is called before each Cadence statement is executed.
+type StatementHook func(fvmEnv fvmRuntime.Environment, inter *interpreter.Interpreter, statement ast.Statement)
+
+type RemoteDebuggerOption func(*RemoteDebugger)
+
+// WithImpersonatedAccounts turns on signature and sequence number checks,
+// except for the given accounts, which can act as proposer, payer or authorizer without keys.
+func WithImpersonatedAccounts(addresses ...flow.Address) RemoteDebuggerOption {
+	return func(d *RemoteDebugger) {
+		if d.impersonated == nil {
+			d.impersonated = NewImpersonatedAccounts()
+		}
+		for _, address := range addresses {
+			d.impersonated[address] = struct{}{}
+		}
+	}
+}
+
+// WithModifiedTransaction marks the transaction as changed after it was signed, e.g. by overrides,
+// so its signatures can not be valid. Impersonated accounts then only turn on the sequence number checks.
+func WithModifiedTransaction() RemoteDebuggerOption {
+	return func(d *RemoteDebugger) {
+		d.modified = true
+	}
+}
+
+// WithStatementHooks calls the hooks before each Cadence statement is executed, after the profile is updated.
+func WithStatementHooks(hooks ...StatementHook) RemoteDebuggerOption {
+	return func(d *RemoteDebugger) {
+		d.statementHooks = append(d.statementHooks, hooks...)
+	}
+}
+
+// WithBlockHeader sets the header of the block the transaction or script is executed in.
+func WithBlockHeader(header *flow.Header) RemoteDebuggerOption {
+	return func(d *RemoteDebugger) {
+		d.ctx = fvm.NewContextFromParent(d.ctx, fvm.WithBlockHeader(header))
+	}
+}
+
+// WithBlocks sets the block lookup used by getBlock(at:).
+func WithBlocks(blocks environment.Blocks) RemoteDebuggerOption {
+	return func(d *RemoteDebugger) {
+		d.ctx = fvm.NewContextFromParent(d.ctx, fvm.WithBlocks(blocks))
+	}
 }
 
 func NewRemoteDebugger(
 	view *debugger.RemoteView,
 	chain flow.Chain,
 	directory string,
-	logger zerolog.Logger) *RemoteDebugger {
+	logger zerolog.Logger,
+	opts ...RemoteDebuggerOption) *RemoteDebugger {
 	vm := fvm.NewVirtualMachine()
+	invoker := newMeteringInvoker()
 
 	profileBuilder := NewProfileBuilder(
 		directory,
 	)
 
+	d := &RemoteDebugger{
+		vm:             vm,
+		view:           view,
+		profileBuilder: profileBuilder,
+		invoker:        invoker,
+	}
+
 	// no signature processor here
 	// TODO Maybe we add fee-deduction step as well
-	ctx := fvm.NewContext(
+	d.ctx = fvm.NewContext(
 		fvm.WithLogger(logger),
 		fvm.WithChain(chain),
-		fvm.WithTransactionProcessors(fvm.NewTransactionInvoker()),
+		fvm.WithTransactionProcessors(invoker),
 		fvm.WithReusableCadenceRuntimePool(fvmRuntime.NewReusableCadenceRuntimePool(
 			1,
 			fvmRuntime.ReusableCadenceRuntimePoolConfig{
-				OnCadenceStatement: profileBuilder.OnCadenceStatement,
+				OnCadenceStatement: d.onCadenceStatement,
 			},
 		)),
 	)
+	d.configure(opts...)
+	return d
+}
 
-	return &RemoteDebugger{
-		ctx:            ctx,
-		vm:             vm,
-		view:           view,
-		profileBuilder: profileBuilder,
+// with returns a copy of the debugger with additional options, e.g. the impersonated accounts of one transaction.
+// The copy shares the view, the profile and the statement hooks with the debugger.
+func (d *RemoteDebugger) with(opts ...RemoteDebuggerOption) *RemoteDebugger {
+	c := *d
+	if d.impersonated != nil {
+		c.impersonated = NewImpersonatedAccounts()
+		for address := range d.impersonated {
+			c.impersonated[address] = struct{}{}
+		}
+	}
+	c.configure(opts...)
+	return &c
+}
+
+func (d *RemoteDebugger) configure(opts ...RemoteDebuggerOption) {
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	if d.impersonated != nil {
+		var processors []fvm.TransactionProcessor
+		if !d.modified {
+			processors = append(processors, NewImpersonationVerifier(d.impersonated))
+		}
+		processors = append(processors,
+			NewImpersonationSequenceNumberChecker(d.impersonated),
+			d.invoker,
+		)
+		d.ctx = fvm.NewContextFromParent(d.ctx, fvm.WithTransactionProcessors(processors...))
+	}
+}
+
+// RunTransaction runs the transaction in the block set with WithBlockHeader.
+// The metering is nil if the transaction was rejected before it was invoked, e.g. by the signature checks.
+func (d *RemoteDebugger) RunTransaction(txBody *flow.TransactionBody) (metering *Metering, txErr, processError error) {
+	tx, metering, err := d.runTransaction(txBody)
+	if err != nil {
+		return nil, nil, err
 	}
+	if tx.Err != nil {
+		return metering, tx.Err, nil
+	}
+	return metering, nil, nil
 }
 
-// RunTransaction runs the transaction given the latest sealed block data
-func (d *RemoteDebugger) RunTransaction(txBody *flow.TransactionBody) (txErr, processError error) {
-	blockCtx := fvm.NewContextFromParent(d.ctx, fvm.WithBlockHeader(d.ctx.BlockHeader))
+func (d *RemoteDebugger) runTransaction(txBody *flow.TransactionBody) (*fvm.TransactionProcedure, *Metering, error) {
+	d.invoker.last = nil
 	tx := fvm.Transaction(txBody, 0)
-	err := d.vm.Run(blockCtx, tx, d.view)
+	err := d.vm.Run(d.ctx, tx, d.view)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return tx.Err, nil
+	return tx, d.invoker.last, nil
 }
 
 func (d *RemoteDebugger) RunScript(code []byte, arguments [][]byte) (value cadence.Value, scriptError, processError error) {
-	scriptCtx := fvm.NewContextFromParent(d.ctx, fvm.WithBlockHeader(d.ctx.BlockHeader))
-	script := fvm.Script(code).WithArguments(arguments...)
-	err := d.vm.Run(scriptCtx, script, d.view)
+	script, err := d.runScript(code, arguments)
 	if err != nil {
 		return nil, nil, err
 	}
 	return script.Value, script.Err, nil
 }
 
+func (d *RemoteDebugger) runScript(code []byte, arguments [][]byte) (*fvm.ScriptProcedure, error) {
+	script := fvm.Script(code).WithArguments(arguments...)
+	err := d.vm.Run(d.ctx, script, d.view)
+	if err != nil {
+		return nil, err
+	}
+	return script, nil
+}
+
+func (d *RemoteDebugger) onCadenceStatement(fvmEnv fvmRuntime.Environment, inter *interpreter.Interpreter, statement ast.Statement) {
+	d.profileBuilder.OnCadenceStatement(fvmEnv, inter, statement)
+	for _, hook := range d.statementHooks {
+		hook(fvmEnv, inter, statement)
+	}
+}
+
 func (d *RemoteDebugger) Close() error {
 	return d.profileBuilder.Close()
 }
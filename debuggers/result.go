@@ -0,0 +1,236 @@
+package debuggers
+
+import (
+	"encoding/hex"
+	stdErrors "errors"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/common"
+	cadenceErrors "github.com/onflow/cadence/runtime/errors"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/execution-debugger/registers"
+	"github.com/onflow/flow-go/fvm"
+	fvmErrors "github.com/onflow/flow-go/fvm/errors"
+	"github.com/onflow/flow-go/model/flow"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+type RunStatus string
+
+const (
+	// RunStatusSuccess means the transaction executed without errors.
+	RunStatusSuccess RunStatus = "success"
+	// RunStatusFailed means the transaction executed and failed with a transaction error.
+	RunStatusFailed RunStatus = "failed"
+	// RunStatusError means the transaction could not be executed, e.g. a register could not be read.
+	RunStatusError RunStatus = "error"
+)
+
+// RunResult is everything known about a transaction run.
+type RunResult struct {
+	// TransactionID is the ID of the transaction on the network.
+	TransactionID string `json:"transactionId"`
+	// ExecutedTransactionID is the ID of the executed transaction, if it was changed before the execution.
+	ExecutedTransactionID string    `json:"executedTransactionId,omitempty"`
+	BlockHeight           uint64    `json:"blockHeight"`
+	Status                RunStatus `json:"status"`
+	Error                 *RunError `json:"error,omitempty"`
+
+	Events   []RunEvent `json:"events"`
+	Logs     []string   `json:"logs"`
+	Metering *Metering  `json:"metering,omitempty"`
+
+	RegistersRead    []registers.RegisterKey `json:"registersRead"`
+	RegistersWritten []RegisterWrite         `json:"registersWritten"`
+
+	// Directory is the run directory, Artifacts are the files written to it.
+	Directory string     `json:"directory"`
+	Artifacts []string   `json:"artifacts"`
+	Timings   RunTimings `json:"timings"`
+}
+
+// ScriptResult is everything known about a script run, the value is JSON-Cadence encoded.
+type ScriptResult struct {
+	BlockHeight     uint64     `json:"blockHeight"`
+	Status          RunStatus  `json:"status"`
+	Error           *RunError  `json:"error,omitempty"`
+	Value           string     `json:"value,omitempty"`
+	Events          []RunEvent `json:"events"`
+	Logs            []string   `json:"logs"`
+	ComputationUsed uint64     `json:"computationUsed"`
+	Directory       string     `json:"directory"`
+}
+
+// RunError is a transaction or execution error, with its FVM error code if it has one.
+type RunError struct {
+	Code    uint16 `json:"code,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+	// Location is the Cadence location of the code that failed, e.g. A.1654653399040a61.FlowToken,
+	// or "transaction" if the transaction code itself failed.
+	Location string `json:"location,omitempty"`
+}
+
+// RunEvent is an event emitted by the transaction, the payload is JSON-Cadence encoded.
+type RunEvent struct {
+	Type       string `json:"type"`
+	EventIndex uint32 `json:"eventIndex"`
+	Payload    string `json:"payload"`
+}
+
+// RegisterWrite is a register set by the transaction, the value is hex encoded and empty if the register was deleted.
+type RegisterWrite struct {
+	Owner string `json:"owner"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// RunTimings are the durations of the run phases, in nanoseconds.
+type RunTimings struct {
+	// Setup is the time to resolve the transaction and prepare the debugger.
+	Setup     time.Duration `json:"setup"`
+	Execution time.Duration `json:"execution"`
+	Total     time.Duration `json:"total"`
+}
+
+// newRunError converts an error to a RunError, keeping the FVM error code.
+func newRunError(err error) *RunError {
+	if err == nil {
+		return nil
+	}
+
+	runErr := &RunError{
+		Message: err.Error(),
+	}
+	if coded := innermostCodedError(err); coded != nil {
+		runErr.Code = uint16(coded.Code())
+		runErr.Name = coded.Code().String()
+	}
+	if location := failingLocation(err); location != nil {
+		switch location.(type) {
+		case common.TransactionLocation:
+			runErr.Location = "transaction"
+		case common.ScriptLocation:
+			runErr.Location = "script"
+		default:
+			runErr.Location = location.String()
+		}
+	}
+	return runErr
+}
+
+// innermostCodedError returns the most specific FVM error in the chain,
+// e.g. the error wrapped by the cadence runtime error that wraps every Cadence failure.
+func innermostCodedError(err error) fvmErrors.CodedError {
+	var innermost fvmErrors.CodedError
+	for err != nil {
+		if coded, ok := err.(fvmErrors.CodedError); ok {
+			innermost = coded
+		}
+		// errors of the FVM environment are recovered by Cadence as external errors, which do not unwrap
+		if external, ok := err.(cadenceErrors.ExternalError); ok {
+			err, _ = external.Recovered.(error)
+			continue
+		}
+		err = stdErrors.Unwrap(err)
+	}
+	return innermost
+}
+
+// importLocated is implemented by Cadence errors that know the location they occurred in.
+type importLocated interface {
+	ImportLocation() common.Location
+}
+
+// failingLocation returns the location of the Cadence code that failed.
+// The location of the interpreter error is the program that was executed, usually the transaction,
+// so the location of the wrapped error is used, then the innermost frame of the stack trace.
+func failingLocation(err error) common.Location {
+	var interpreterErr interpreter.Error
+	if !fvmErrors.As(err, &interpreterErr) {
+		return nil
+	}
+
+	var located importLocated
+	if stdErrors.As(interpreterErr.Err, &located) && located.ImportLocation() != nil {
+		return located.ImportLocation()
+	}
+	if len(interpreterErr.StackTrace) > 0 {
+		location := interpreterErr.StackTrace[len(interpreterErr.StackTrace)-1].LocationRange.Location
+		if location != nil {
+			return location
+		}
+	}
+	return interpreterErr.Location
+}
+
+// newScriptResult converts an executed script, the value is JSON-Cadence encoded.
+func newScriptResult(blockHeight uint64, directory string, script *fvm.ScriptProcedure) (*ScriptResult, error) {
+	result := &ScriptResult{
+		BlockHeight:     blockHeight,
+		Status:          RunStatusSuccess,
+		Events:          newRunEvents(script.Events),
+		Logs:            script.Logs,
+		ComputationUsed: script.GasUsed,
+		Directory:       directory,
+	}
+	if script.Err != nil {
+		result.Status = RunStatusFailed
+		result.Error = newRunError(script.Err)
+	}
+	if script.Value != nil {
+		value, err := jsoncdc.Encode(script.Value)
+		if err != nil {
+			return nil, err
+		}
+		result.Value = string(value)
+	}
+	return result, nil
+}
+
+func newRunEvents(events []flow.Event) []RunEvent {
+	runEvents := make([]RunEvent, 0, len(events))
+	for _, event := range events {
+		runEvents = append(runEvents, RunEvent{
+			Type:       string(event.Type),
+			EventIndex: event.EventIndex,
+			Payload:    string(event.Payload),
+		})
+	}
+	return runEvents
+}
+
+func newRegisterWrites(ids []flow.RegisterID, values []flow.RegisterValue) []RegisterWrite {
+	writes := make([]RegisterWrite, 0, len(ids))
+	for i, id := range ids {
+		key := registers.RegisterKey{Owner: id.Owner, Key: id.Key}.ToReadable()
+		writes = append(writes, RegisterWrite{
+			Owner: key.Owner,
+			Key:   key.Key,
+			Value: hex.EncodeToString(values[i]),
+		})
+	}
+	return writes
+}
+
+// listArtifacts lists the paths of the files in the run directory.
+func listArtifacts(directory string) ([]string, error) {
+	var artifacts []string
+	err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		artifacts = append(artifacts, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(artifacts)
+	return artifacts, nil
+}
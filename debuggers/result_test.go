@@ -0,0 +1,82 @@
+package debuggers
+
+import (
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/common"
+	cadenceErrors "github.com/onflow/cadence/runtime/errors"
+	"github.com/onflow/cadence/runtime/interpreter"
+	fvmErrors "github.com/onflow/flow-go/fvm/errors"
+	"github.com/onflow/flow-go/model/flow"
+	"testing"
+)
+
+func TestNewRunError(t *testing.T) {
+	txLocation := common.TransactionLocation{0x1}
+	tokenLocation := common.AddressLocation{Address: common.Address{0x1}, Name: "Token"}
+	marketLocation := common.AddressLocation{Address: common.Address{0x2}, Name: "Market"}
+
+	cadenceError := func(err error, stackTrace ...interpreter.Invocation) error {
+		return fvmErrors.NewCadenceRuntimeError(runtime.Error{
+			Err: interpreter.Error{
+				Err:        err,
+				Location:   txLocation,
+				StackTrace: stackTrace,
+			},
+			Location: txLocation,
+		})
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		code     uint16
+		location string
+	}{
+		{
+			name: "condition in a contract",
+			err: cadenceError(interpreter.ConditionError{
+				LocationRange: interpreter.LocationRange{Location: tokenLocation},
+			}),
+			code:     uint16(fvmErrors.ErrCodeCadenceRunTimeError),
+			location: tokenLocation.String(),
+		},
+		{
+			name: "innermost stack frame",
+			err: cadenceError(
+				interpreter.DivisionByZeroError{},
+				interpreter.Invocation{LocationRange: interpreter.LocationRange{Location: marketLocation}},
+				interpreter.Invocation{LocationRange: interpreter.LocationRange{Location: tokenLocation}},
+			),
+			code:     uint16(fvmErrors.ErrCodeCadenceRunTimeError),
+			location: tokenLocation.String(),
+		},
+		{
+			name:     "transaction",
+			err:      cadenceError(interpreter.DivisionByZeroError{}),
+			code:     uint16(fvmErrors.ErrCodeCadenceRunTimeError),
+			location: "transaction",
+		},
+		{
+			name:     "environment error",
+			err:      cadenceError(cadenceErrors.NewExternalError(fvmErrors.NewAccountNotFoundError(flow.HexToAddress("01")))),
+			code:     uint16(fvmErrors.ErrCodeAccountNotFoundError),
+			location: "transaction",
+		},
+		{
+			name: "not coded",
+			err:  cadenceErrors.NewDefaultUserError("oops"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			runErr := newRunError(test.err)
+			if runErr.Code != test.code {
+				t.Errorf("code is %d, expected %d", runErr.Code, test.code)
+			}
+			if runErr.Location != test.location {
+				t.Errorf("location is %q, expected %q", runErr.Location, test.location)
+			}
+		})
+	}
+}
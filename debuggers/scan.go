@@ -0,0 +1,244 @@
+package debuggers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/onflow/execution-debugger"
+	"github.com/onflow/flow-dps/api/dps"
+	"github.com/rs/zerolog"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+const scanReportFilename = "scan.json"
+
+// FailedTransaction is a transaction whose sealed result is an error, and the outcome of re-executing it.
+type FailedTransaction struct {
+	TransactionID string `json:"transactionId"`
+	BlockHeight   uint64 `json:"blockHeight"`
+	SealedError   string `json:"sealedError"`
+	// Reproduced is set if the re-execution failed as well.
+	Reproduced bool      `json:"reproduced"`
+	Error      *RunError `json:"error,omitempty"`
+	Directory  string    `json:"directory,omitempty"`
+	// RunError is set if the transaction could not be re-executed.
+	RunError string `json:"runError,omitempty"`
+}
+
+// FailureGroup is a set of failed transactions with the same error code and failing location.
+type FailureGroup struct {
+	Code         uint16   `json:"code"`
+	Name         string   `json:"name"`
+	Location     string   `json:"location"`
+	Count        int      `json:"count"`
+	Transactions []string `json:"transactions"`
+}
+
+// ScanReport lists the failed transactions of a height range grouped by cause, the largest groups first.
+type ScanReport struct {
+	StartHeight  uint64              `json:"startHeight"`
+	EndHeight    uint64              `json:"endHeight"`
+	Transactions int                 `json:"transactions"`
+	Failed       []FailedTransaction `json:"failed"`
+	Groups       []FailureGroup      `json:"groups"`
+}
+
+// Scanner finds the failed transactions in a height range and re-executes them to find the cause.
+type Scanner struct {
+	client dps.APIClient
+	batch  *BatchDebugger
+	log    zerolog.Logger
+}
+
+// NewScanner creates a scanner that reads the blocks using the archive client
+// and re-executes the failed transactions with the batch debugger.
+func NewScanner(client dps.APIClient, batch *BatchDebugger, logger zerolog.Logger) *Scanner {
+	return &Scanner{
+		client: client,
+		batch:  batch,
+		log:    logger,
+	}
+}
+
+// Scan scans the blocks from startHeight to endHeight, both included.
+func (s *Scanner) Scan(ctx context.Context, startHeight, endHeight uint64) (*ScanReport, error) {
+	report := &ScanReport{
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+	}
+
+	var entries []BatchEntry
+	var err error
+	report.Transactions, err = scanHeights(ctx, s.client, startHeight, endHeight, func(height uint64, network *debugger.NetworkTransactions) error {
+		result, err := network.TransactionResult()
+		if err != nil {
+			return err
+		}
+		if result.ErrorMessage == "" {
+			return nil
+		}
+
+		report.Failed = append(report.Failed, FailedTransaction{
+			TransactionID: network.ID.String(),
+			BlockHeight:   height,
+			SealedError:   result.ErrorMessage,
+		})
+		entries = append(entries, BatchEntry{
+			Name:     network.ID.String(),
+			Resolver: network,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.log.Info().
+		Int("transactions", report.Transactions).
+		Int("failed", len(report.Failed)).
+		Msg("Scanned blocks.")
+
+	results := s.batch.Run(ctx, entries)
+	for i, r := range results {
+		failed := &report.Failed[i]
+		if r.Result == nil {
+			failed.RunError = r.Error
+			continue
+		}
+		failed.Directory = r.Result.Directory
+		failed.Error = r.Result.Error
+		failed.Reproduced = r.Result.Status == RunStatusFailed
+		if r.Result.Status == RunStatusError && r.Result.Error != nil {
+			failed.RunError = r.Result.Error.Message
+		}
+	}
+
+	report.Groups = groupFailures(report.Failed)
+	return report, nil
+}
+
+// BlockRangeEntries returns a batch entry for each transaction of the blocks from startHeight to endHeight.
+func BlockRangeEntries(ctx context.Context, client dps.APIClient, startHeight, endHeight uint64) ([]BatchEntry, error) {
+	var entries []BatchEntry
+	_, err := scanHeights(ctx, client, startHeight, endHeight, func(height uint64, network *debugger.NetworkTransactions) error {
+		entries = append(entries, BatchEntry{
+			Name:     network.ID.String(),
+			Resolver: network,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// scanHeights calls fn for each transaction of the blocks from startHeight to endHeight,
+// and returns the number of transactions.
+func scanHeights(
+	ctx context.Context,
+	client dps.APIClient,
+	startHeight, endHeight uint64,
+	fn func(height uint64, network *debugger.NetworkTransactions) error) (int, error) {
+	if startHeight > endHeight {
+		return 0, fmt.Errorf("start height %d must not be higher than end height %d", startHeight, endHeight)
+	}
+
+	count := 0
+	for height := startHeight; height <= endHeight; height++ {
+		if ctx.Err() != nil {
+			return count, ctx.Err()
+		}
+
+		txIDs, err := debugger.ListBlockTransactions(client, height)
+		if err != nil {
+			return count, err
+		}
+		for _, txID := range txIDs {
+			err := fn(height, &debugger.NetworkTransactions{
+				Client: client,
+				ID:     txID,
+			})
+			if err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+func groupFailures(failed []FailedTransaction) []FailureGroup {
+	type groupKey struct {
+		code     uint16
+		location string
+	}
+
+	groups := make(map[groupKey]*FailureGroup)
+	var keys []groupKey
+	for _, f := range failed {
+		key := groupKey{}
+		name := "not reproduced"
+		if f.Reproduced {
+			key = groupKey{code: f.Error.Code, location: f.Error.Location}
+			name = f.Error.Name
+		}
+
+		group, ok := groups[key]
+		if !ok {
+			group = &FailureGroup{
+				Code:     key.code,
+				Name:     name,
+				Location: key.location,
+			}
+			groups[key] = group
+			keys = append(keys, key)
+		}
+		group.Count++
+		group.Transactions = append(group.Transactions, f.TransactionID)
+	}
+
+	result := make([]FailureGroup, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, *groups[key])
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Count > result[j].Count
+	})
+	return result
+}
+
+// WriteReport writes the groups table to w and the full report as JSON to the batch directory.
+func (s *Scanner) WriteReport(report *ScanReport, w io.Writer) error {
+	_, _ = fmt.Fprintf(w, "%d of %d transactions failed between heights %d and %d\n",
+		len(report.Failed), report.Transactions, report.StartHeight, report.EndHeight)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "Count\tCode\tError\tLocation")
+	for _, group := range report.Groups {
+		code := ""
+		if group.Code != 0 {
+			code = strconv.Itoa(int(group.Code))
+		}
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", group.Count, code, group.Name, group.Location)
+	}
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	filename := filepath.Join(s.batch.directory, scanReportFilename)
+	err = os.MkdirAll(filepath.Dir(filename), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
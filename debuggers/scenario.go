@@ -0,0 +1,315 @@
+package debuggers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/onflow/execution-debugger"
+	"github.com/onflow/flow-dps/api/dps"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+)
+
+const scenarioReportFilename = "scenario.json"
+
+// Scenario is a sequence of transactions and scripts executed on top of the state at Height.
+// The steps of a branch share one view, so each step sees the registers written by the steps before it.
+type Scenario struct {
+	Height uint64         `json:"height"`
+	Steps  []ScenarioStep `json:"steps"`
+
+	// dir is the directory of the scenario file, paths in the steps are relative to it
+	dir string
+}
+
+// ScenarioStep is a transaction, given by its network ID or by a transaction file,
+// or a script with JSON-Cadence encoded arguments.
+// The height of a transaction is ignored, it is executed at the height of the scenario.
+// The accounts impersonated by a transaction file are only impersonated for its own step.
+// A step can also save the state written so far as a named snapshot, or roll the state back to a snapshot,
+// so alternative transactions can be explored from the same intermediate state.
+// A step can fork the state into a named branch, and a later step can switch to a branch, so alternatives
+// can be run side by side. The steps run on the "main" branch until the first switch.
+type ScenarioStep struct {
+	Name            string            `json:"name"`
+	Snapshot        string            `json:"snapshot,omitempty"`
+	Rollback        string            `json:"rollback,omitempty"`
+	Fork            string            `json:"fork,omitempty"`
+	Branch          string            `json:"branch,omitempty"`
+	Transaction     string            `json:"tx,omitempty"`
+	TransactionFile string            `json:"txFile,omitempty"`
+	ScriptFile      string            `json:"script,omitempty"`
+	Arguments       []json.RawMessage `json:"arguments,omitempty"`
+	Expect          *SuiteExpectation `json:"expect,omitempty"`
+}
+
+// LoadScenario reads a scenario file.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	scenario := &Scenario{}
+	err = json.Unmarshal(data, scenario)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode scenario %s: %w", path, err)
+	}
+	scenario.dir = filepath.Dir(path)
+	return scenario, nil
+}
+
+// Path resolves a path of a step relative to the scenario file.
+func (s *Scenario) Path(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(s.dir, path)
+}
+
+// scenarioMainBranch is the branch the steps of a scenario start on.
+const scenarioMainBranch = "main"
+
+// ScenarioReport is the result of running a scenario, the steps are in the order of the scenario.
+// RegistersWritten are the registers written by the steps on Branch, the branch the scenario ended on.
+type ScenarioReport struct {
+	Height           uint64            `json:"height"`
+	Branch           string            `json:"branch"`
+	Passed           int               `json:"passed"`
+	Failed           int               `json:"failed"`
+	Steps            []SuiteCaseResult `json:"steps"`
+	RegistersWritten []RegisterWrite   `json:"registersWritten"`
+}
+
+// StepResolverFunc resolves the transaction of a scenario step.
+type StepResolverFunc func(scenario *Scenario, step ScenarioStep) (debugger.TransactionResolver, error)
+
+// ScenarioRunner executes scenarios.
+type ScenarioRunner struct {
+	debugger *TransactionDebugger
+	log      zerolog.Logger
+}
+
+// NewScenarioRunner creates a scenario runner, the options configure the register and block header sources
+// the same way as for the TransactionDebugger.
+func NewScenarioRunner(
+	dpsClients []dps.APIClient,
+	chain flow.Chain,
+	logger zerolog.Logger,
+	opts ...TransactionDebuggerOption) *ScenarioRunner {
+	return &ScenarioRunner{
+		debugger: NewTransactionDebugger(nil, dpsClients, chain, logger, opts...),
+		log:      logger,
+	}
+}
+
+// Run executes the steps in order. A step passes if it could be run and its outcome matches the expectation.
+// If a step cannot be run, e.g. because a register could not be read, the remaining steps are skipped.
+func (r *ScenarioRunner) Run(ctx context.Context, scenario *Scenario, resolve StepResolverFunc) (*ScenarioReport, error) {
+	d := r.debugger
+	readFunc, closeCache, err := d.cachedRegisterReader(scenario.Height)
+	if err != nil {
+		return nil, err
+	}
+	defer closeCache()
+	view, err := d.newView(readFunc, d.readWrappers...)
+	if err != nil {
+		return nil, err
+	}
+	debuggerOpts, err := d.remoteDebuggerOptions(scenario.Height)
+	if err != nil {
+		return nil, err
+	}
+	dbg := NewRemoteDebugger(view, d.chain, d.directory, d.fvmLog, debuggerOpts...)
+	defer func() {
+		err := dbg.Close()
+		if err != nil {
+			r.log.Warn().
+				Err(err).
+				Msg("Could not close debugger.")
+		}
+	}()
+
+	report := &ScenarioReport{
+		Height: scenario.Height,
+		Branch: scenarioMainBranch,
+		Steps:  make([]SuiteCaseResult, len(scenario.Steps)),
+	}
+	branches := map[string]*debugger.RemoteView{scenarioMainBranch: view}
+	skip := ""
+	for i, step := range scenario.Steps {
+		result := &report.Steps[i]
+		result.Name = step.Name
+		if result.Name == "" {
+			result.Name = strconv.Itoa(i)
+		}
+
+		if skip == "" && ctx.Err() != nil {
+			skip = ctx.Err().Error()
+		}
+		if skip != "" {
+			result.Error = skip
+		} else {
+			outcome, err := r.runStep(dbg, branches, &report.Branch, scenario, step, resolve)
+			if err != nil {
+				result.Error = err.Error()
+				skip = fmt.Sprintf("skipped, step %s could not be run", result.Name)
+			}
+			result.Outcome = outcome
+		}
+
+		if result.Outcome != nil {
+			result.Failures = result.Outcome.Check(step.Expect)
+		}
+		result.Passed = result.Error == "" && len(result.Failures) == 0
+		if result.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+
+		r.log.Info().
+			Str("step", result.Name).
+			Bool("passed", result.Passed).
+			Msg("Scenario step.")
+	}
+
+	report.RegistersWritten = newRegisterWrites(branches[report.Branch].RegisterUpdates())
+	return report, nil
+}
+
+// runStep runs the step on the view of the current branch, fork and branch steps change the branches.
+func (r *ScenarioRunner) runStep(
+	dbg *RemoteDebugger,
+	branches map[string]*debugger.RemoteView,
+	branch *string,
+	scenario *Scenario,
+	step ScenarioStep,
+	resolve StepResolverFunc) (*SuiteOutcome, error) {
+	view := branches[*branch]
+	switch {
+	case step.Snapshot != "":
+		view.Snapshot(step.Snapshot)
+		return nil, nil
+	case step.Rollback != "":
+		return nil, view.Rollback(step.Rollback)
+	case step.Fork != "":
+		if _, ok := branches[step.Fork]; ok {
+			return nil, fmt.Errorf("branch %s already exists", step.Fork)
+		}
+		branches[step.Fork] = view.Fork()
+		return nil, nil
+	case step.Branch != "":
+		if _, ok := branches[step.Branch]; !ok {
+			return nil, fmt.Errorf("no branch named %s", step.Branch)
+		}
+		*branch = step.Branch
+		return nil, nil
+	}
+	// transactions and scripts run on the view of the current branch
+	dbg.view = view
+
+	if step.ScriptFile != "" {
+		code, err := os.ReadFile(scenario.Path(step.ScriptFile))
+		if err != nil {
+			return nil, err
+		}
+		arguments := make([][]byte, 0, len(step.Arguments))
+		for _, argument := range step.Arguments {
+			arguments = append(arguments, argument)
+		}
+
+		script, err := dbg.runScript(code, arguments)
+		if err != nil {
+			return nil, err
+		}
+		result, err := newScriptResult(scenario.Height, r.debugger.directory, script)
+		if err != nil {
+			return nil, err
+		}
+		return newScriptOutcome(result), nil
+	}
+
+	resolver, err := resolve(scenario, step)
+	if err != nil {
+		return nil, err
+	}
+	txBody, err := resolver.TransactionBody()
+	if err != nil {
+		return nil, err
+	}
+	stepOpts, err := stepDebuggerOptions(resolver, txBody)
+	if err != nil {
+		return nil, err
+	}
+	tx, metering, err := dbg.with(stepOpts...).runTransaction(txBody)
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := &SuiteOutcome{
+		Status: RunStatusSuccess,
+		Events: eventTypes(newRunEvents(tx.Events)),
+	}
+	if tx.Err != nil {
+		outcome.Status = RunStatusFailed
+		outcome.Error = newRunError(tx.Err)
+	}
+	if metering != nil {
+		outcome.ComputationUsed = metering.ComputationUsed
+	}
+	return outcome, nil
+}
+
+// stepDebuggerOptions returns the RemoteDebugger options of a transaction step,
+// the accounts impersonated by its resolver and whether the resolver modified the transaction.
+func stepDebuggerOptions(resolver debugger.TransactionResolver, txBody *flow.TransactionBody) ([]RemoteDebuggerOption, error) {
+	opts, err := impersonationOptions(resolver)
+	if err != nil {
+		return nil, err
+	}
+	modified, err := transactionModified(resolver, txBody)
+	if err != nil {
+		return nil, err
+	}
+	if modified {
+		opts = append(opts, WithModifiedTransaction())
+	}
+	return opts, nil
+}
+
+// WriteReport writes the steps to w and the full report as JSON to the debugger directory.
+func (r *ScenarioRunner) WriteReport(report *ScenarioReport, w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, result := range report.Steps {
+		status := "PASS"
+		if !result.Passed {
+			status = "FAIL"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", status, result.Name, result.Error)
+		for _, failure := range result.Failures {
+			_, _ = fmt.Fprintf(tw, "\t\t%s\n", failure)
+		}
+	}
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(w, "%d passed, %d failed, %d registers written\n", report.Passed, report.Failed, len(report.RegistersWritten))
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	filename := filepath.Join(r.debugger.directory, scenarioReportFilename)
+	err = os.MkdirAll(filepath.Dir(filename), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
@@ -0,0 +1,122 @@
+package debuggers
+
+import (
+	"github.com/onflow/execution-debugger"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog"
+	"testing"
+)
+
+func TestScenarioBranchSteps(t *testing.T) {
+	view := debugger.NewRemoteView(func(owner string, key string) (flow.RegisterValue, error) {
+		return nil, nil
+	})
+	err := view.Set("owner", "key", flow.RegisterValue("main"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	branches := map[string]*debugger.RemoteView{scenarioMainBranch: view}
+	branch := scenarioMainBranch
+	runner := &ScenarioRunner{log: zerolog.Nop()}
+
+	steps := []struct {
+		step   ScenarioStep
+		branch string
+		err    bool
+	}{
+		{step: ScenarioStep{Fork: "alternative"}, branch: scenarioMainBranch},
+		{step: ScenarioStep{Fork: "alternative"}, branch: scenarioMainBranch, err: true},
+		{step: ScenarioStep{Branch: "missing"}, branch: scenarioMainBranch, err: true},
+		{step: ScenarioStep{Branch: "alternative"}, branch: "alternative"},
+	}
+	for _, s := range steps {
+		_, err := runner.runStep(nil, branches, &branch, &Scenario{}, s.step, nil)
+		if (err != nil) != s.err {
+			t.Fatalf("step %+v: error %v", s.step, err)
+		}
+		if branch != s.branch {
+			t.Fatalf("step %+v: branch is %s, expected %s", s.step, branch, s.branch)
+		}
+	}
+
+	err = branches[branch].Set("owner", "key", flow.RegisterValue("alternative"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, expected := range map[string]string{scenarioMainBranch: "main", "alternative": "alternative"} {
+		value, err := branches[name].Get("owner", "key")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(value) != expected {
+			t.Errorf("value on branch %s is %q, expected %q", name, value, expected)
+		}
+	}
+}
+
+// impersonatingResolver impersonates the accounts in the transaction.
+type impersonatingResolver struct {
+	debugger.CustomTransaction
+	accounts []flow.Address
+}
+
+func (r *impersonatingResolver) ImpersonatedAccounts() ([]flow.Address, error) {
+	return r.accounts, nil
+}
+
+func TestScenarioStepImpersonation(t *testing.T) {
+	base := flow.HexToAddress("01")
+	dbg := NewRemoteDebugger(nil, flow.Emulator.Chain(), t.TempDir(), zerolog.Nop(), WithImpersonatedAccounts(base))
+	txBody := flow.NewTransactionBody().SetScript([]byte("transaction {}"))
+
+	tests := []struct {
+		name         string
+		resolver     debugger.TransactionResolver
+		impersonated []flow.Address
+		modified     bool
+	}{
+		{
+			name:         "no impersonation",
+			resolver:     &debugger.CustomTransaction{Tx: txBody},
+			impersonated: []flow.Address{base},
+		},
+		{
+			name:         "impersonation",
+			resolver:     &impersonatingResolver{CustomTransaction: debugger.CustomTransaction{Tx: txBody}, accounts: []flow.Address{flow.HexToAddress("02")}},
+			impersonated: []flow.Address{base, flow.HexToAddress("02")},
+		},
+		{
+			name: "impersonation of an overridden transaction",
+			resolver: &debugger.OverriddenTransaction{
+				Resolver: &impersonatingResolver{CustomTransaction: debugger.CustomTransaction{Tx: txBody}, accounts: []flow.Address{flow.HexToAddress("03")}},
+				GasLimit: 200,
+			},
+			impersonated: []flow.Address{base, flow.HexToAddress("03")},
+			modified:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stepBody, err := tt.resolver.TransactionBody()
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts, err := stepDebuggerOptions(tt.resolver, stepBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			step := dbg.with(opts...)
+			if len(step.impersonated) != len(tt.impersonated) || step.modified != tt.modified {
+				t.Errorf("step impersonates %v, modified %v, expected %v, modified %v", step.impersonated.Strings(), step.modified, tt.impersonated, tt.modified)
+			}
+			for _, address := range tt.impersonated {
+				if !step.impersonated.Contains(address) {
+					t.Errorf("%s is not impersonated", address.HexWithPrefix())
+				}
+			}
+			if len(dbg.impersonated) != 1 || dbg.modified {
+				t.Errorf("step changed the scenario debugger, it impersonates %v", dbg.impersonated.Strings())
+			}
+		})
+	}
+}
@@ -0,0 +1,388 @@
+package debuggers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/onflow/execution-debugger"
+	"github.com/onflow/execution-debugger/registers"
+	"github.com/rs/zerolog"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+)
+
+const suiteReportFilename = "suite.json"
+
+// Suite is a regression suite, transactions and scripts with the outcomes they are expected to have.
+type Suite struct {
+	Cases []SuiteCase `json:"cases"`
+
+	// dir is the directory of the suite file, paths in the cases are relative to it
+	dir string
+}
+
+// SuiteCase is a transaction or a script of a suite.
+// A transaction is given by its network ID or by a transaction file (see debugger.FileTransaction),
+// a script by its file, JSON-Cadence encoded arguments and the height it is executed at.
+type SuiteCase struct {
+	Name            string            `json:"name"`
+	Transaction     string            `json:"tx,omitempty"`
+	TransactionFile string            `json:"txFile,omitempty"`
+	ScriptFile      string            `json:"script,omitempty"`
+	Arguments       []json.RawMessage `json:"arguments,omitempty"`
+	Height          uint64            `json:"height,omitempty"`
+	Expect          *SuiteExpectation `json:"expect,omitempty"`
+}
+
+// SuiteExpectation is the expected outcome of a case, only the fields that are set are checked.
+// Events are the event types in emission order, an empty list expects no events.
+type SuiteExpectation struct {
+	Status         RunStatus       `json:"status,omitempty"`
+	ErrorCode      uint16          `json:"errorCode,omitempty"`
+	Events         []string        `json:"events"`
+	Value          json.RawMessage `json:"value,omitempty"`
+	MinComputation uint64          `json:"minComputation,omitempty"`
+	MaxComputation uint64          `json:"maxComputation,omitempty"`
+}
+
+// SuiteOutcome is the actual outcome of a case.
+type SuiteOutcome struct {
+	Status          RunStatus       `json:"status"`
+	Error           *RunError       `json:"error,omitempty"`
+	Events          []string        `json:"events"`
+	Value           json.RawMessage `json:"value,omitempty"`
+	ComputationUsed uint64          `json:"computationUsed"`
+}
+
+// Check returns the differences between the outcome and the expectation.
+func (o *SuiteOutcome) Check(expect *SuiteExpectation) []string {
+	if expect == nil {
+		return nil
+	}
+
+	var failures []string
+	if expect.Status != "" && expect.Status != o.Status {
+		failures = append(failures, fmt.Sprintf("status is %s, expected %s", o.Status, expect.Status))
+	}
+	errorCode := uint16(0)
+	if o.Error != nil {
+		errorCode = o.Error.Code
+	}
+	if expect.ErrorCode != 0 && expect.ErrorCode != errorCode {
+		failures = append(failures, fmt.Sprintf("error code is %d, expected %d", errorCode, expect.ErrorCode))
+	}
+	if expect.Events != nil && !equalStrings(expect.Events, o.Events) {
+		failures = append(failures, fmt.Sprintf("events are %v, expected %v", o.Events, expect.Events))
+	}
+	if expect.Value != nil && !equalJSON(expect.Value, o.Value) {
+		failures = append(failures, fmt.Sprintf("value is %s, expected %s", o.Value, expect.Value))
+	}
+	if expect.MinComputation != 0 && o.ComputationUsed < expect.MinComputation {
+		failures = append(failures, fmt.Sprintf("computation %d is below %d", o.ComputationUsed, expect.MinComputation))
+	}
+	if expect.MaxComputation != 0 && o.ComputationUsed > expect.MaxComputation {
+		failures = append(failures, fmt.Sprintf("computation %d is above %d", o.ComputationUsed, expect.MaxComputation))
+	}
+	return failures
+}
+
+// Expectation returns an expectation matching the outcome,
+// the computation bounds are the computation used plus or minus the tolerance, a fraction.
+func (o *SuiteOutcome) Expectation(tolerance float64) *SuiteExpectation {
+	expect := &SuiteExpectation{
+		Status:         o.Status,
+		Events:         append([]string{}, o.Events...),
+		Value:          o.Value,
+		MinComputation: uint64(float64(o.ComputationUsed) * (1 - tolerance)),
+		MaxComputation: uint64(float64(o.ComputationUsed)*(1+tolerance)) + 1,
+	}
+	if o.Error != nil {
+		expect.ErrorCode = o.Error.Code
+	}
+	return expect
+}
+
+// LoadSuite reads a suite file.
+func LoadSuite(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	suite := &Suite{}
+	err = json.Unmarshal(data, suite)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode suite %s: %w", path, err)
+	}
+	suite.dir = filepath.Dir(path)
+	return suite, nil
+}
+
+// Write writes the suite file.
+func (s *Suite) Write(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Path resolves a path of a case relative to the suite file.
+func (s *Suite) Path(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(s.dir, path)
+}
+
+// SuiteCaseResult is the outcome of a case and whether it matched the expectation.
+// Outcome is nil if the case could not be run.
+type SuiteCaseResult struct {
+	Name      string        `json:"name"`
+	Passed    bool          `json:"passed"`
+	Failures  []string      `json:"failures,omitempty"`
+	Outcome   *SuiteOutcome `json:"outcome,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	Directory string        `json:"directory,omitempty"`
+}
+
+// SuiteReport is the result of running a suite, the cases are in the order of the suite.
+type SuiteReport struct {
+	Passed int               `json:"passed"`
+	Failed int               `json:"failed"`
+	Cases  []SuiteCaseResult `json:"cases"`
+}
+
+// Update sets the expectations of the suite to the outcomes of the report.
+// Cases that could not be run keep their expectations.
+func (r *SuiteReport) Update(suite *Suite, tolerance float64) {
+	for i, result := range r.Cases {
+		if result.Outcome == nil || result.Outcome.Status == RunStatusError {
+			continue
+		}
+		suite.Cases[i].Expect = result.Outcome.Expectation(tolerance)
+	}
+}
+
+// TransactionResolverFunc resolves the transaction of a suite case.
+type TransactionResolverFunc func(suite *Suite, c SuiteCase) (debugger.TransactionResolver, error)
+
+// SuiteRunner runs the cases of a suite and checks their outcomes.
+// Transactions are run by the batch debugger, scripts one after the other with the same options.
+type SuiteRunner struct {
+	batch *BatchDebugger
+	log   zerolog.Logger
+}
+
+func NewSuiteRunner(batch *BatchDebugger, logger zerolog.Logger) *SuiteRunner {
+	return &SuiteRunner{
+		batch: batch,
+		log:   logger,
+	}
+}
+
+// Run runs the suite, a case passes if it could be run and its outcome matches the expectation.
+func (r *SuiteRunner) Run(ctx context.Context, suite *Suite, resolve TransactionResolverFunc) *SuiteReport {
+	results := make([]SuiteCaseResult, len(suite.Cases))
+
+	var entries []BatchEntry
+	var entryCases []int
+	for i, c := range suite.Cases {
+		results[i].Name = c.Name
+		if results[i].Name == "" {
+			results[i].Name = strconv.Itoa(i)
+		}
+		if c.ScriptFile != "" {
+			continue
+		}
+
+		resolver, err := resolve(suite, c)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		entries = append(entries, BatchEntry{
+			Name:     results[i].Name,
+			Resolver: resolver,
+		})
+		entryCases = append(entryCases, i)
+	}
+
+	for j, batchResult := range r.batch.Run(ctx, entries) {
+		i := entryCases[j]
+		if batchResult.Result == nil {
+			results[i].Error = batchResult.Error
+			continue
+		}
+		results[i].Outcome = newTransactionOutcome(batchResult.Result)
+		results[i].Directory = batchResult.Result.Directory
+		if batchResult.Result.Status == RunStatusError {
+			results[i].Error = batchResult.Error
+		}
+	}
+
+	r.runScripts(ctx, suite, results)
+
+	report := &SuiteReport{
+		Cases: results,
+	}
+	for i := range results {
+		result := &results[i]
+		if result.Outcome != nil {
+			result.Failures = result.Outcome.Check(suite.Cases[i].Expect)
+		}
+		result.Passed = result.Error == "" && len(result.Failures) == 0
+		if result.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+	}
+	return report
+}
+
+func (r *SuiteRunner) runScripts(ctx context.Context, suite *Suite, results []SuiteCaseResult) {
+	caches := registers.NewRegisterCacheSet(r.log)
+	defer func() {
+		err := caches.Close()
+		if err != nil {
+			r.log.Warn().
+				Err(err).
+				Msg("Could not close register caches.")
+		}
+	}()
+
+	for i, c := range suite.Cases {
+		if c.ScriptFile == "" {
+			continue
+		}
+		if ctx.Err() != nil {
+			results[i].Error = ctx.Err().Error()
+			continue
+		}
+		if c.Height == 0 {
+			results[i].Error = "script case has no height"
+			continue
+		}
+
+		code, err := os.ReadFile(suite.Path(c.ScriptFile))
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		arguments := make([][]byte, 0, len(c.Arguments))
+		for _, argument := range c.Arguments {
+			arguments = append(arguments, argument)
+		}
+
+		opts := append([]TransactionDebuggerOption{}, r.batch.opts...)
+		opts = append(opts,
+			WithRegisterCaches(caches),
+			WithDirectory(filepath.Join(r.batch.directory, fmt.Sprintf("script_%04d_%s", i, results[i].Name))),
+		)
+		logger := r.log.With().Str("case", results[i].Name).Logger()
+		result, err := NewTransactionDebugger(nil, r.batch.dpsClients, r.batch.chain, logger, opts...).
+			RunScript(c.Height, code, arguments)
+		if err != nil {
+			results[i].Error = err.Error()
+			logger.Warn().
+				Err(err).
+				Msg("Could not run script.")
+		}
+		if result == nil {
+			continue
+		}
+		results[i].Outcome = newScriptOutcome(result)
+		results[i].Directory = result.Directory
+	}
+}
+
+func newTransactionOutcome(result *RunResult) *SuiteOutcome {
+	outcome := &SuiteOutcome{
+		Status: result.Status,
+		Error:  result.Error,
+		Events: eventTypes(result.Events),
+	}
+	if result.Metering != nil {
+		outcome.ComputationUsed = result.Metering.ComputationUsed
+	}
+	return outcome
+}
+
+func newScriptOutcome(result *ScriptResult) *SuiteOutcome {
+	outcome := &SuiteOutcome{
+		Status:          result.Status,
+		Error:           result.Error,
+		Events:          eventTypes(result.Events),
+		ComputationUsed: result.ComputationUsed,
+	}
+	if result.Value != "" {
+		outcome.Value = json.RawMessage(result.Value)
+	}
+	return outcome
+}
+
+func eventTypes(events []RunEvent) []string {
+	types := make([]string, 0, len(events))
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// equalJSON compares two JSON documents ignoring insignificant whitespace.
+func equalJSON(a json.RawMessage, b json.RawMessage) bool {
+	var compactA, compactB bytes.Buffer
+	if json.Compact(&compactA, a) != nil || json.Compact(&compactB, b) != nil {
+		return bytes.Equal(bytes.TrimSpace(a), bytes.TrimSpace(b))
+	}
+	return bytes.Equal(compactA.Bytes(), compactB.Bytes())
+}
+
+// WriteReport writes the failed cases to w and the full report as JSON to the batch directory.
+func (r *SuiteRunner) WriteReport(report *SuiteReport, w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, result := range report.Cases {
+		if result.Passed {
+			continue
+		}
+		if result.Error != "" {
+			_, _ = fmt.Fprintf(tw, "FAIL\t%s\t%s\n", result.Name, result.Error)
+		}
+		for _, failure := range result.Failures {
+			_, _ = fmt.Fprintf(tw, "FAIL\t%s\t%s\n", result.Name, failure)
+		}
+	}
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(w, "%d passed, %d failed\n", report.Passed, report.Failed)
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	filename := filepath.Join(r.batch.directory, suiteReportFilename)
+	err = os.MkdirAll(filepath.Dir(filename), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
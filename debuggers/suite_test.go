@@ -0,0 +1,128 @@
+package debuggers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/onflow/execution-debugger"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog"
+	"testing"
+)
+
+func TestSuiteOutcomeCheck(t *testing.T) {
+	outcome := &SuiteOutcome{
+		Status:          RunStatusFailed,
+		Error:           &RunError{Code: 1101},
+		Events:          []string{"A.1.Token.Withdrawn"},
+		Value:           json.RawMessage(`{"type":"Int","value":"1"}`),
+		ComputationUsed: 100,
+	}
+
+	tests := []struct {
+		name     string
+		expect   *SuiteExpectation
+		failures int
+	}{
+		{name: "no expectation"},
+		{name: "empty expectation", expect: &SuiteExpectation{}},
+		{
+			name: "everything matches",
+			expect: &SuiteExpectation{
+				Status:         RunStatusFailed,
+				ErrorCode:      1101,
+				Events:         []string{"A.1.Token.Withdrawn"},
+				Value:          json.RawMessage(`{ "type": "Int", "value": "1" }`),
+				MinComputation: 100,
+				MaxComputation: 100,
+			},
+		},
+		{name: "status", expect: &SuiteExpectation{Status: RunStatusSuccess}, failures: 1},
+		{name: "error code", expect: &SuiteExpectation{ErrorCode: 1007}, failures: 1},
+		{name: "events", expect: &SuiteExpectation{Events: []string{}}, failures: 1},
+		{name: "value", expect: &SuiteExpectation{Value: json.RawMessage(`{"type":"Int","value":"2"}`)}, failures: 1},
+		{name: "computation below", expect: &SuiteExpectation{MinComputation: 101}, failures: 1},
+		{name: "computation above", expect: &SuiteExpectation{MaxComputation: 99}, failures: 1},
+		{
+			name:     "everything differs",
+			expect:   &SuiteExpectation{Status: RunStatusSuccess, ErrorCode: 1007, Events: []string{}, MaxComputation: 1},
+			failures: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failures := outcome.Check(tt.expect)
+			if len(failures) != tt.failures {
+				t.Errorf("failures are %v, expected %d failures", failures, tt.failures)
+			}
+		})
+	}
+}
+
+func TestSuiteOutcomeExpectation(t *testing.T) {
+	tests := []struct {
+		name        string
+		computation uint64
+		tolerance   float64
+		min, max    uint64
+	}{
+		{name: "no tolerance", computation: 100, tolerance: 0, min: 100, max: 101},
+		{name: "ten percent", computation: 100, tolerance: 0.1, min: 90, max: 111},
+		{name: "no computation", computation: 0, tolerance: 0.1, min: 0, max: 1},
+		{name: "full tolerance", computation: 100, tolerance: 1, min: 0, max: 201},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome := &SuiteOutcome{
+				Status:          RunStatusSuccess,
+				Events:          []string{"A.1.Token.Deposited"},
+				ComputationUsed: tt.computation,
+			}
+			expect := outcome.Expectation(tt.tolerance)
+			if expect.MinComputation != tt.min || expect.MaxComputation != tt.max {
+				t.Errorf("bounds are [%d, %d], expected [%d, %d]", expect.MinComputation, expect.MaxComputation, tt.min, tt.max)
+			}
+			if failures := outcome.Check(expect); len(failures) != 0 {
+				t.Errorf("outcome does not meet its own expectation: %v", failures)
+			}
+		})
+	}
+}
+
+func TestEqualJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  string
+		equal bool
+	}{
+		{name: "same", a: `{"a":1}`, b: `{"a":1}`, equal: true},
+		{name: "whitespace", a: `{"a": 1}`, b: "{\n  \"a\":1\n}", equal: true},
+		{name: "different values", a: `{"a":1}`, b: `{"a":2}`},
+		{name: "different key order", a: `{"a":1,"b":2}`, b: `{"b":2,"a":1}`},
+		{name: "invalid but same", a: `{"a": `, b: ` {"a": `, equal: true},
+		{name: "invalid and different", a: `{"a": `, b: `{"b": `},
+		{name: "empty", a: ``, b: ``, equal: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if equalJSON(json.RawMessage(tt.a), json.RawMessage(tt.b)) != tt.equal {
+				t.Errorf("equalJSON(%s, %s) is %v, expected %v", tt.a, tt.b, !tt.equal, tt.equal)
+			}
+		})
+	}
+}
+
+func TestSuiteRunnerScriptWithoutHeight(t *testing.T) {
+	batch := NewBatchDebugger(nil, flow.Emulator.Chain(), zerolog.Nop(), 1, t.TempDir())
+	runner := NewSuiteRunner(batch, zerolog.Nop())
+	suite := &Suite{
+		Cases: []SuiteCase{{Name: "script", ScriptFile: "script.cdc"}},
+	}
+
+	report := runner.Run(context.Background(), suite, func(*Suite, SuiteCase) (debugger.TransactionResolver, error) {
+		t.Fatal("script cases are not resolved")
+		return nil, nil
+	})
+	if report.Failed != 1 || report.Cases[0].Error != "script case has no height" {
+		t.Errorf("report is %+v, expected the script case to fail for its missing height", report)
+	}
+}
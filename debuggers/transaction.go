@@ -2,121 +2,268 @@ package debuggers
 
 import (
 	"context"
-	"encoding/csv"
-	"encoding/json"
 	"fmt"
 	"github.com/onflow/execution-debugger"
 	"github.com/onflow/execution-debugger/registers"
 	"github.com/onflow/flow-dps/api/dps"
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/rs/zerolog"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 	"io"
-	"math/rand"
 	"os"
 	"path/filepath"
 	"strconv"
-	"strings"
+	"time"
 )
 
+// TransactionDebugger runs a transaction against the registers read from archive nodes.
+// The archive clients are injected and their connections are owned by the caller,
+// so one set of connections (see debugger.ArchivePool) can be shared by many runs.
 type TransactionDebugger struct {
-	txResolver  debugger.TransactionResolver
-	dpsClient   dps.APIClient
-	archiveHost string
-	chain       flow.Chain
-	directory   string
-	log         zerolog.Logger
+	txResolver     debugger.TransactionResolver
+	dpsClients     []dps.APIClient
+	crossCheckRate float64
+	readerFactory  registers.RegisterReaderFactory
+	blockHeaders   debugger.BlockHeaders
+	caches         *registers.RegisterCacheSet
+	noFileCache    bool
+	readWrappers   []registers.RegisterGetWrapper
+	patches        *debugger.StatePatches
+	debuggerOpts   []RemoteDebuggerOption
+	chain          flow.Chain
+	directory      string
+	log            zerolog.Logger
+	// fvmLog receives the logs of the FVM, they are dropped unless WithFVMLogs is used
+	fvmLog zerolog.Logger
+}
+
+type TransactionDebuggerOption func(*TransactionDebugger)
+
+// WithCrossCheckRate sets the fraction of register reads that are verified against a second archive node.
+func WithCrossCheckRate(rate float64) TransactionDebuggerOption {
+	return func(d *TransactionDebugger) {
+		d.crossCheckRate = rate
+	}
+}
+
+// WithRegisterReaderFactory reads registers using the given factory instead of the archive clients,
+// e.g. to run against an execution node or a local checkpoint.
+func WithRegisterReaderFactory(factory registers.RegisterReaderFactory) TransactionDebuggerOption {
+	return func(d *TransactionDebugger) {
+		d.readerFactory = factory
+	}
+}
+
+// WithBlockHeaders sets the source of the block header installed for the execution height
+// and of the blocks returned by getBlock(at:), by default the first archive client is used.
+func WithBlockHeaders(blockHeaders debugger.BlockHeaders) TransactionDebuggerOption {
+	return func(d *TransactionDebugger) {
+		d.blockHeaders = blockHeaders
+	}
+}
+
+// WithRegisterCaches reads registers through the shared caches instead of a cache owned by the run.
+func WithRegisterCaches(caches *registers.RegisterCacheSet) TransactionDebuggerOption {
+	return func(d *TransactionDebugger) {
+		d.caches = caches
+	}
+}
+
+// WithoutRegisterFileCache reads every register from the register reader, without the per height cache file.
+// It is used for register sources that do not read the state of a height, e.g. a checkpoint read at a fixed state
+// commitment, so their registers are not mixed with registers read from the network, or replayed with -offline.
+func WithoutRegisterFileCache() TransactionDebuggerOption {
+	return func(d *TransactionDebugger) {
+		d.noFileCache = true
+	}
+}
+
+// WithRegisterGetWrappers adds wrappers around the register reads of the run, e.g. to count the reads of a contract.
+// The wrappers are owned by the caller and are not closed by the run.
+func WithRegisterGetWrappers(wrappers ...registers.RegisterGetWrapper) TransactionDebuggerOption {
+	return func(d *TransactionDebugger) {
+		d.readWrappers = append(d.readWrappers, wrappers...)
+	}
+}
+
+// WithStatePatches applies the patches to the state before the transaction or script is executed.
+// The registers written by the patches are not part of the registers written by the run.
+func WithStatePatches(patches *debugger.StatePatches) TransactionDebuggerOption {
+	return func(d *TransactionDebugger) {
+		d.patches = patches
+	}
+}
+
+// WithDirectory sets the directory the run artifacts are written to.
+// Transaction runs default to a directory named after the transaction ID, scripts require a directory.
+func WithDirectory(directory string) TransactionDebuggerOption {
+	return func(d *TransactionDebugger) {
+		d.directory = directory
+	}
+}
+
+// WithFVMLogs passes the logs of the FVM through to the given writer.
+func WithFVMLogs(w io.Writer) TransactionDebuggerOption {
+	return func(d *TransactionDebugger) {
+		d.fvmLog = d.log.Output(w)
+	}
+}
+
+// WithRemoteDebuggerOptions passes options to the RemoteDebugger executing the transaction.
+func WithRemoteDebuggerOptions(opts ...RemoteDebuggerOption) TransactionDebuggerOption {
+	return func(d *TransactionDebugger) {
+		d.debuggerOpts = append(d.debuggerOpts, opts...)
+	}
 }
 
 func NewTransactionDebugger(
 	txResolver debugger.TransactionResolver,
-	archiveHost string,
-	dpsClient dps.APIClient,
+	dpsClients []dps.APIClient,
 	chain flow.Chain,
-	logger zerolog.Logger) *TransactionDebugger {
-
-	return &TransactionDebugger{
-		txResolver:  txResolver,
-		archiveHost: archiveHost,
-		chain:       chain,
-		dpsClient:   dpsClient,
+	logger zerolog.Logger,
+	opts ...TransactionDebuggerOption) *TransactionDebugger {
 
-		directory: fmt.Sprintf("t_%d", rand.Intn(1000)), // TODO remove
+	d := &TransactionDebugger{
+		txResolver: txResolver,
+		dpsClients: dpsClients,
+		chain:      chain,
 
-		log: logger,
+		log:    logger,
+		fvmLog: zerolog.Nop(),
+	}
+	for _, opt := range opts {
+		opt(d)
 	}
+	if d.blockHeaders == nil && len(dpsClients) > 0 {
+		d.blockHeaders = &debugger.NetworkBlockHeaders{Client: dpsClients[0]}
+	}
+	return d
 }
 
-type clientWithConnection struct {
-	dps.APIClient
-	*grpc.ClientConn
-}
+// RunTransaction runs the transaction and returns the result of the run.
+// An error is returned if the run could not be set up, if the execution itself fails
+// the result is returned with the error status together with the error.
+func (d *TransactionDebugger) RunTransaction(ctx context.Context) (*RunResult, error) {
+	start := time.Now()
 
-func (d *TransactionDebugger) RunTransaction(ctx context.Context) (txErr, processError error) {
-	client, err := d.getClient()
+	blockHeight, err := d.txResolver.BlockHeight()
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		err := client.Close()
-		if err != nil {
-			d.log.Warn().
-				Err(err).
-				Msg("Could not close client connection.")
-		}
-	}()
-
-	blockHeight, err := d.txResolver.BlockHeight()
+	txBody, err := d.txResolver.TransactionBody()
+	if err != nil {
+		return nil, err
+	}
+	err = d.defaultDirectory(txBody)
 	if err != nil {
 		return nil, err
 	}
 
-	cache, err := registers.NewRemoteRegisterFileCache(blockHeight, d.log)
+	readFunc, closeCache, err := d.cachedRegisterReader(blockHeight)
 	if err != nil {
 		return nil, err
 	}
+	defer closeCache()
 
+	readTracker := registers.NewRemoteRegisterReadTracker(d.directory, d.log)
 	wrappers := []registers.RegisterGetWrapper{
-		cache,
-		registers.NewRemoteRegisterReadTracker(d.directory, d.log),
+		readTracker,
 		registers.NewCaptureContractWrapper(d.directory, d.log),
 	}
+	view, err := d.newView(readFunc, append(wrappers, d.readWrappers...)...)
+	if err != nil {
+		return nil, err
+	}
 
-	readFunc := registers.NewRemoteReader(client, blockHeight)
-	readFunc.Wrap(wrappers...)
+	debuggerOpts, err := d.remoteDebuggerOptions(blockHeight)
+	if err != nil {
+		return nil, err
+	}
 
-	view := debugger.NewRemoteView(readFunc)
+	//err = d.dumpTransactionToFile(txBody)
 
-	logInterceptor := NewLogInterceptor(d.log, d.directory)
-	defer func() {
-		err := logInterceptor.Close()
+	manifest := &RunManifest{
+		TransactionID: txBody.ID().String(),
+		BlockHeight:   blockHeight,
+		Chain:         d.chain.String(),
+	}
+	if identifier, ok := d.txResolver.(debugger.TransactionIdentifier); ok {
+		txID, err := identifier.TransactionID()
 		if err != nil {
-			d.log.Warn().
-				Err(err).
-				Msg("Could not close log interceptor.")
+			return nil, err
 		}
-	}()
+		if txID != txBody.ID() {
+			manifest.TransactionID = txID.String()
+			manifest.ExecutedTransactionID = txBody.ID().String()
+			debuggerOpts = append(debuggerOpts, WithModifiedTransaction())
+		}
+	}
 
-	dbg := NewRemoteDebugger(view, d.chain, d.directory, d.log.Output(logInterceptor))
-	defer func(debugger *RemoteDebugger) {
-		err := debugger.Close()
+	dbg := NewRemoteDebugger(view, d.chain, d.directory, d.fvmLog, debuggerOpts...)
+	if d.patches != nil {
+		manifest.Patches = d.patches.Changes()
+	}
+	if dbg.impersonated != nil {
+		manifest.Impersonated = dbg.impersonated.Strings()
+	}
+	if describer, ok := d.txResolver.(debugger.ChangeDescriber); ok {
+		manifest.Changes, err = describer.Changes()
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = manifest.Write(d.directory)
+	if err != nil {
+		d.log.Warn().
+			Err(err).
+			Msg("Could not write run manifest.")
+	}
+
+	result := &RunResult{
+		TransactionID:         manifest.TransactionID,
+		ExecutedTransactionID: manifest.ExecutedTransactionID,
+		BlockHeight:           blockHeight,
+		Directory:             d.directory,
+	}
+
+	executionStart := time.Now()
+	result.Timings.Setup = executionStart.Sub(start)
+	tx, metering, processErr := dbg.runTransaction(txBody)
+	result.Timings.Execution = time.Since(executionStart)
+
+	switch {
+	case processErr != nil:
+		result.Status = RunStatusError
+		result.Error = newRunError(processErr)
+	case tx.Err != nil:
+		result.Status = RunStatusFailed
+		result.Error = newRunError(tx.Err)
+	default:
+		result.Status = RunStatusSuccess
+	}
+	if tx != nil {
+		result.Events = newRunEvents(tx.Events)
+		result.Logs = tx.Logs
+	}
+	result.Metering = metering
+	result.RegistersRead = readTracker.RegistersRead()
+	result.RegistersWritten = newRegisterWrites(view.RegisterUpdates())
+
+	if metering != nil {
+		err := metering.WriteCSV(d.directory)
 		if err != nil {
 			d.log.Warn().
 				Err(err).
-				Msg("Could not close debugger.")
+				Msg("Could not write computation intensities.")
 		}
-	}(dbg)
-
-	//err = d.dumpTransactionToFile(txBody)
+	}
 
-	txBody, err := d.txResolver.TransactionBody()
+	err = dbg.Close()
 	if err != nil {
-		return nil, err
+		d.log.Warn().
+			Err(err).
+			Msg("Could not close debugger.")
 	}
 
-	txErr, err = dbg.RunTransaction(txBody)
-
 	for _, wrapper := range wrappers {
 		switch w := wrapper.(type) {
 		case io.Closer:
@@ -129,130 +276,251 @@ func (d *TransactionDebugger) RunTransaction(ctx context.Context) (txErr, proces
 		}
 	}
 
-	return txErr, err
-}
-
-func (d *TransactionDebugger) getClient() (clientWithConnection, error) {
-	conn, err := grpc.Dial(
-		d.archiveHost,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	result.Artifacts, err = listArtifacts(d.directory)
 	if err != nil {
-		d.log.Error().
+		d.log.Warn().
 			Err(err).
-			Str("host", d.archiveHost).
-			Msg("Could not connect to server.")
-		return clientWithConnection{}, err
+			Msg("Could not list run artifacts.")
 	}
-	client := dps.NewAPIClient(conn)
+	result.Timings.Total = time.Since(start)
 
-	return clientWithConnection{
-		APIClient:  client,
-		ClientConn: conn,
-	}, nil
+	return result, processErr
 }
 
-func (d *TransactionDebugger) dumpTransactionToFile(body flow.TransactionBody) error {
-	filename := d.directory + "/transaction.cdc"
-	err := os.MkdirAll(filepath.Dir(filename), os.ModePerm)
+// RunScript runs the script at the given height, using the registers and block headers of the debugger.
+// An error is returned if the run could not be set up, if the execution itself fails
+// the result is returned with the error status together with the error.
+func (d *TransactionDebugger) RunScript(blockHeight uint64, code []byte, arguments [][]byte) (*ScriptResult, error) {
+	if d.directory == "" {
+		return nil, fmt.Errorf("a directory is required to run scripts, use WithDirectory")
+	}
+	readFunc, closeCache, err := d.cachedRegisterReader(blockHeight)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	file, err := os.Create(filename)
+	defer closeCache()
+	view, err := d.newView(readFunc, d.readWrappers...)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer func(csvFile *os.File) {
-		err := csvFile.Close()
+
+	debuggerOpts, err := d.remoteDebuggerOptions(blockHeight)
+	if err != nil {
+		return nil, err
+	}
+	dbg := NewRemoteDebugger(view, d.chain, d.directory, d.fvmLog, debuggerOpts...)
+	defer func() {
+		err := dbg.Close()
 		if err != nil {
 			d.log.Warn().
 				Err(err).
-				Msg("Could not close file.")
+				Msg("Could not close debugger.")
 		}
-	}(file)
+	}()
 
-	_, err = file.WriteString(string(body.Script))
-	return err
+	script, processErr := dbg.runScript(code, arguments)
+	if processErr != nil {
+		return &ScriptResult{
+			BlockHeight: blockHeight,
+			Status:      RunStatusError,
+			Error:       newRunError(processErr),
+			Directory:   d.directory,
+		}, processErr
+	}
+	return newScriptResult(blockHeight, d.directory, script)
 }
 
-type LogInterceptor struct {
-	ComputationIntensities map[uint64]uint64 `json:"computationIntensities"`
-	MemoryIntensities      map[uint64]uint64 `json:"memoryIntensities"`
+// newView creates the view the transaction or script is executed on, reading through the wrappers.
+// If there are state patches, they are applied to a parent view,
+// so the returned view only holds the registers written by the execution.
+func (d *TransactionDebugger) newView(readFunc registers.RegisterGetRegisterFunc, wrappers ...registers.RegisterGetWrapper) (*debugger.RemoteView, error) {
+	patchReadFunc := readFunc
+	readFunc.Wrap(wrappers...)
+	view := debugger.NewRemoteView(readFunc)
+	if d.patches == nil {
+		return view, nil
+	}
 
-	log      zerolog.Logger
-	filename string
+	// the patches read through their own view, so the wrappers of the run do not see their reads
+	patched := debugger.NewRemoteView(patchReadFunc)
+	err := applyStatePatches(patched, d.patches, d.chain, d.directory, d.fvmLog)
+	if err != nil {
+		return nil, fmt.Errorf("could not apply state patches: %w", err)
+	}
+	ids, values := patched.RegisterUpdates()
+	for i, id := range ids {
+		err := view.Set(id.Owner, id.Key, values[i])
+		if err != nil {
+			return nil, fmt.Errorf("could not apply state patches: %w", err)
+		}
+	}
+	return view.NewChild().(*debugger.RemoteView), nil
+}
+
+// cachedRegisterReader creates the register reader for the given height, reading through the shared caches
+// or through a cache owned by the caller, which is closed by the returned function.
+func (d *TransactionDebugger) cachedRegisterReader(blockHeight uint64) (registers.RegisterGetRegisterFunc, func(), error) {
+	readFunc, err := d.newRegisterReader(blockHeight)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cache, closeCache, err := d.registerFileCache(blockHeight)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cache != nil {
+		readFunc.Wrap(cache)
+	}
+	return readFunc, closeCache, nil
+}
+
+// registerFileCache returns the register file cache of the height, the shared cache if there is one,
+// and a function closing the cache if it is owned by the caller.
+// The cache is nil if the file cache is disabled.
+func (d *TransactionDebugger) registerFileCache(blockHeight uint64) (registers.RegisterGetWrapper, func(), error) {
+	if d.noFileCache {
+		return nil, func() {}, nil
+	}
+
+	if d.caches != nil {
+		cache, err := d.caches.Cache(blockHeight)
+		if err != nil {
+			return nil, nil, err
+		}
+		return cache, func() {}, nil
+	}
+
+	cache, err := registers.NewRemoteRegisterFileCache(blockHeight, d.log)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cache, func() {
+		err := cache.Close()
+		if err != nil {
+			d.log.Warn().
+				Err(err).
+				Msg("Could not close register cache.")
+		}
+	}, nil
 }
 
-func NewLogInterceptor(log zerolog.Logger, directory string) *LogInterceptor {
-	return &LogInterceptor{
-		ComputationIntensities: map[uint64]uint64{},
-		MemoryIntensities:      map[uint64]uint64{},
-		log:                    log,
-		filename:               directory + "/computation_intensities.csv",
+// newRegisterReader creates the register reader for the given height,
+// by default the reads are spread over the archive clients.
+func (d *TransactionDebugger) newRegisterReader(blockHeight uint64) (registers.RegisterGetRegisterFunc, error) {
+	if d.readerFactory != nil {
+		return d.readerFactory(blockHeight)
+	}
+
+	reader, err := registers.NewMultiRemoteReader(d.dpsClients, blockHeight, d.crossCheckRate, d.log)
+	if err != nil {
+		return nil, err
 	}
+	return reader.RegisterFunc(), nil
 }
 
-var _ io.Writer = &LogInterceptor{}
+// defaultDirectory names the directory of the run after the network ID of the transaction,
+// unless it was set with WithDirectory.
+func (d *TransactionDebugger) defaultDirectory(txBody *flow.TransactionBody) error {
+	if d.directory != "" {
+		return nil
+	}
+	txID := txBody.ID()
+	if identifier, ok := d.txResolver.(debugger.TransactionIdentifier); ok {
+		var err error
+		txID, err = identifier.TransactionID()
+		if err != nil {
+			return err
+		}
+	}
+	d.directory = txID.String()
+	return nil
+}
 
-type computationIntensitiesLog struct {
-	ComputationIntensities map[uint64]uint64 `json:"computationIntensities"`
-	MemoryIntensities      map[uint64]uint64 `json:"memoryIntensities"`
+// transactionModified returns whether the resolved transaction differs from the one on the network,
+// e.g. because of overrides, so its signatures no longer match its body.
+func transactionModified(resolver debugger.TransactionResolver, txBody *flow.TransactionBody) (bool, error) {
+	identifier, ok := resolver.(debugger.TransactionIdentifier)
+	if !ok {
+		return false, nil
+	}
+	txID, err := identifier.TransactionID()
+	if err != nil {
+		return false, err
+	}
+	return txID != txBody.ID(), nil
 }
 
-func (l *LogInterceptor) Write(p []byte) (n int, err error) {
-	fmt.Println("#log", string(p))
+// remoteDebuggerOptions returns the configured RemoteDebugger options
+// together with the block header and block lookup for the given height
+// and the accounts impersonated by the transaction resolver.
+func (d *TransactionDebugger) remoteDebuggerOptions(blockHeight uint64) ([]RemoteDebuggerOption, error) {
+	debuggerOpts := append([]RemoteDebuggerOption{}, d.debuggerOpts...)
 
-	if strings.Contains(string(p), "computationIntensities") {
-		var log computationIntensitiesLog
-		err := json.Unmarshal(p, &log)
+	if d.blockHeaders != nil {
+		header, err := d.blockHeaders.BlockHeader(blockHeight)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
-		l.ComputationIntensities = log.ComputationIntensities
-		l.MemoryIntensities = log.MemoryIntensities
+		debuggerOpts = append(debuggerOpts,
+			WithBlockHeader(header),
+			WithBlocks(&debugger.BlockFinder{Headers: d.blockHeaders}),
+		)
+	}
 
-		return len(p), nil
+	impersonationOpts, err := impersonationOptions(d.txResolver)
+	if err != nil {
+		return nil, err
 	}
-	return len(p), nil
+	return append(debuggerOpts, impersonationOpts...), nil
 }
 
-func (l *LogInterceptor) Close() error {
-	err := os.MkdirAll(filepath.Dir(l.filename), os.ModePerm)
+// impersonationOptions returns the RemoteDebugger options for the accounts impersonated by the resolver.
+func impersonationOptions(resolver debugger.TransactionResolver) ([]RemoteDebuggerOption, error) {
+	impersonator, ok := resolver.(debugger.Impersonator)
+	if !ok {
+		return nil, nil
+	}
+	addresses, err := impersonator.ImpersonatedAccounts()
+	if err != nil {
+		return nil, err
+	}
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+	return []RemoteDebuggerOption{WithImpersonatedAccounts(addresses...)}, nil
+}
+
+func (d *TransactionDebugger) dumpTransactionToFile(body flow.TransactionBody) error {
+	filename := d.directory + "/transaction.cdc"
+	err := os.MkdirAll(filepath.Dir(filename), os.ModePerm)
 	if err != nil {
 		return err
 	}
-	csvFile, err := os.Create(l.filename)
+	file, err := os.Create(filename)
 	if err != nil {
 		return err
 	}
 	defer func(csvFile *os.File) {
 		err := csvFile.Close()
 		if err != nil {
-			l.log.Warn().
+			d.log.Warn().
 				Err(err).
-				Msg("Could not close csv file.")
+				Msg("Could not close file.")
 		}
-	}(csvFile)
+	}(file)
 
-	writer := csv.NewWriter(csvFile)
-	defer writer.Flush()
-	err = writer.Write([]string{"*Computation Kind", "Intensity"})
-	if err != nil {
-		return err
-	}
-	for i, q := range l.ComputationIntensities {
-		key, ok := computationKindNameMap[i]
-		if !ok {
-			key = strconv.Itoa(int(i))
-		}
+	_, err = file.WriteString(string(body.Script))
+	return err
+}
 
-		err := writer.Write([]string{key, strconv.Itoa(int(q))})
-		if err != nil {
-			return err
-		}
+// ComputationKindName returns the name of the computation kind, or its number if it is not known.
+func ComputationKindName(kind uint64) string {
+	name, ok := computationKindNameMap[kind]
+	if !ok {
+		return strconv.FormatUint(kind, 10)
 	}
-
-	return nil
+	return name
 }
 
 var computationKindNameMap = map[uint64]string{
@@ -0,0 +1,214 @@
+package debuggers
+
+import (
+	"fmt"
+	"github.com/onflow/execution-debugger"
+	"github.com/onflow/execution-debugger/registers"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog"
+	"os"
+	"testing"
+)
+
+func TestCachedRegisterReader(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chdir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	factory := func(blockHeight uint64) (registers.RegisterGetRegisterFunc, error) {
+		return func(owner string, key string) (flow.RegisterValue, error) {
+			return flow.RegisterValue("value"), nil
+		}, nil
+	}
+
+	tests := []struct {
+		name      string
+		opts      []TransactionDebuggerOption
+		height    uint64
+		cacheFile bool
+	}{
+		{
+			name:      "file cache",
+			opts:      []TransactionDebuggerOption{WithRegisterReaderFactory(factory)},
+			height:    1,
+			cacheFile: true,
+		},
+		{
+			name:   "without file cache",
+			opts:   []TransactionDebuggerOption{WithRegisterReaderFactory(factory), WithoutRegisterFileCache()},
+			height: 2,
+		},
+		{
+			name: "without file cache and shared caches",
+			opts: []TransactionDebuggerOption{
+				WithRegisterReaderFactory(factory),
+				WithoutRegisterFileCache(),
+				WithRegisterCaches(registers.NewRegisterCacheSet(zerolog.Nop())),
+			},
+			height: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewTransactionDebugger(nil, nil, flow.Emulator.Chain(), zerolog.Nop(), tt.opts...)
+			readFunc, closeCache, err := d.cachedRegisterReader(tt.height)
+			if err != nil {
+				t.Fatal(err)
+			}
+			value, err := readFunc("owner", "key")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(value) != "value" {
+				t.Errorf("value is %q", value)
+			}
+			closeCache()
+			if d.caches != nil {
+				err = d.caches.Close()
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err = os.Stat(fmt.Sprintf("block-%d-cache.csv", tt.height))
+			if tt.cacheFile != (err == nil) {
+				t.Errorf("cache file written: %v, expected %v", err == nil, tt.cacheFile)
+			}
+		})
+	}
+}
+
+func TestTransactionModified(t *testing.T) {
+	original := flow.NewTransactionBody().
+		SetScript([]byte("transaction {}")).
+		SetGasLimit(100)
+	custom := &debugger.CustomTransaction{Tx: original}
+
+	tests := []struct {
+		name     string
+		resolver debugger.TransactionResolver
+		modified bool
+	}{
+		{name: "custom transaction", resolver: custom},
+		{name: "overridden gas limit", resolver: &debugger.OverriddenTransaction{Resolver: custom, GasLimit: 200}, modified: true},
+		{name: "same gas limit", resolver: &debugger.OverriddenTransaction{Resolver: custom, GasLimit: 100}},
+		{name: "execution height", resolver: &debugger.ExecutionHeightOverride{Resolver: custom, Height: 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txBody, err := tt.resolver.TransactionBody()
+			if err != nil {
+				t.Fatal(err)
+			}
+			modified, err := transactionModified(tt.resolver, txBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if modified != tt.modified {
+				t.Errorf("modified is %v, expected %v", modified, tt.modified)
+			}
+		})
+	}
+}
+
+// readCounter counts the registers read through it.
+type readCounter struct {
+	reads int
+}
+
+func (c *readCounter) Wrap(inner registers.RegisterGetRegisterFunc) registers.RegisterGetRegisterFunc {
+	return func(owner string, key string) (flow.RegisterValue, error) {
+		c.reads++
+		return inner(owner, key)
+	}
+}
+
+func TestNewViewRegisterPatches(t *testing.T) {
+	account := string(flow.HexToAddress("01").Bytes())
+	patches := &debugger.StatePatches{
+		Registers: []debugger.RegisterPatch{
+			{Key: registers.RegisterKey{Owner: "", Key: "uuid"}, Value: flow.RegisterValue("patched uuid")},
+			{Key: registers.RegisterKey{Owner: account, Key: "key"}, Value: flow.RegisterValue("patched key")},
+		},
+	}
+	d := NewTransactionDebugger(nil, nil, flow.Emulator.Chain(), zerolog.Nop(), WithStatePatches(patches))
+	counter := &readCounter{}
+	view, err := d.newView(func(owner string, key string) (flow.RegisterValue, error) {
+		return flow.RegisterValue("remote"), nil
+	}, counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, patch := range patches.Registers {
+		value, err := view.Get(patch.Key.Owner, patch.Key.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(value) != string(patch.Value) {
+			t.Errorf("register %s is %q, expected %q", patch.Key, value, patch.Value)
+		}
+	}
+	if counter.reads != 0 {
+		t.Errorf("patched registers read %d times through the wrappers", counter.reads)
+	}
+
+	value, err := view.Get(account, "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(value) != "remote" || counter.reads != 1 {
+		t.Errorf("register is %q after %d reads through the wrappers", value, counter.reads)
+	}
+	if ids, _ := view.RegisterUpdates(); len(ids) != 0 {
+		t.Errorf("patches are part of the registers written: %v", ids)
+	}
+}
+
+func TestDefaultDirectory(t *testing.T) {
+	txBody := flow.NewTransactionBody().SetScript([]byte("transaction {}"))
+	networkID := flow.Identifier{1, 2, 3}
+
+	tests := []struct {
+		name      string
+		resolver  debugger.TransactionResolver
+		opts      []TransactionDebuggerOption
+		directory string
+	}{
+		{name: "custom transaction", resolver: &debugger.CustomTransaction{Tx: txBody}, directory: txBody.ID().String()},
+		{
+			name:      "overridden network transaction",
+			resolver:  &debugger.OverriddenTransaction{Resolver: &debugger.NetworkTransactions{ID: networkID}, GasLimit: 200},
+			directory: networkID.String(),
+		},
+		{
+			name:      "directory option",
+			resolver:  &debugger.CustomTransaction{Tx: txBody},
+			opts:      []TransactionDebuggerOption{WithDirectory("run")},
+			directory: "run",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewTransactionDebugger(tt.resolver, nil, flow.Emulator.Chain(), zerolog.Nop(), tt.opts...)
+			err := d.defaultDirectory(txBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.directory != tt.directory {
+				t.Errorf("directory is %s, expected %s", d.directory, tt.directory)
+			}
+		})
+	}
+
+	_, err := NewTransactionDebugger(nil, nil, flow.Emulator.Chain(), zerolog.Nop()).RunScript(1, nil, nil)
+	if err == nil {
+		t.Error("scripts without a directory must be an error")
+	}
+}
@@ -0,0 +1,251 @@
+package debuggers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/onflow/execution-debugger/registers"
+	"github.com/rs/zerolog"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+const upgradeReportFilename = "upgrade.json"
+
+// UpgradeOutcome is the outcome of one of the two runs of a transaction.
+type UpgradeOutcome struct {
+	Status          RunStatus `json:"status"`
+	Error           string    `json:"error,omitempty"`
+	ComputationUsed uint64    `json:"computationUsed"`
+	Directory       string    `json:"directory,omitempty"`
+}
+
+// EventDiff is an event that differs between the runs, at the same index.
+// An event is nil if the run emitted fewer events.
+type EventDiff struct {
+	Index    int       `json:"index"`
+	Original *RunEvent `json:"original"`
+	Upgraded *RunEvent `json:"upgraded"`
+}
+
+// UpgradeDiff is the difference between running a transaction with the original and with the upgraded code.
+// In Registers, Before is the value written by the original run and After the one written by the upgraded run,
+// a value is empty if the run did not write or deleted the register.
+type UpgradeDiff struct {
+	Name          string          `json:"name"`
+	TransactionID string          `json:"transactionId"`
+	BlockHeight   uint64          `json:"blockHeight"`
+	Original      *UpgradeOutcome `json:"original"`
+	Upgraded      *UpgradeOutcome `json:"upgraded"`
+	StatusChanged bool            `json:"statusChanged"`
+	Events        []EventDiff     `json:"events,omitempty"`
+	Registers     []RegisterDiff  `json:"registers,omitempty"`
+	// ComputationDelta is the computation of the upgraded run minus the computation of the original run.
+	ComputationDelta int64 `json:"computationDelta"`
+}
+
+// Changed reports whether the upgrade changed the status, the events or the written registers.
+// A different computation alone is not a change.
+func (d *UpgradeDiff) Changed() bool {
+	return d.StatusChanged || len(d.Events) > 0 || len(d.Registers) > 0
+}
+
+// UpgradeReport is the impact of a contract upgrade on a set of transactions.
+type UpgradeReport struct {
+	Transactions int           `json:"transactions"`
+	Changed      int           `json:"changed"`
+	Diffs        []UpgradeDiff `json:"diffs"`
+	// Errors are the transactions that could not be run, by name.
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// UpgradeAnalyzer runs each transaction twice, with the deployed and with the upgraded contract code,
+// and compares the results.
+type UpgradeAnalyzer struct {
+	batch    *BatchDebugger
+	override *registers.ContractCodeOverride
+	log      zerolog.Logger
+}
+
+// NewUpgradeAnalyzer creates an analyzer that replaces the contract code using the override.
+func NewUpgradeAnalyzer(batch *BatchDebugger, override *registers.ContractCodeOverride, logger zerolog.Logger) *UpgradeAnalyzer {
+	return &UpgradeAnalyzer{
+		batch:    batch,
+		override: override,
+		log:      logger,
+	}
+}
+
+// Analyze runs the entries with the original and the upgraded code.
+// Both runs of an entry are in the same batch, so they share the register caches.
+func (a *UpgradeAnalyzer) Analyze(ctx context.Context, entries []BatchEntry) *UpgradeReport {
+	names := make([]string, len(entries))
+	runs := make([]BatchEntry, 0, 2*len(entries))
+	for i, entry := range entries {
+		name := entry.Name
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		names[i] = name
+
+		original := entry
+		original.Name = name + "_original"
+		upgraded := entry
+		upgraded.Name = name + "_upgraded"
+		upgraded.Options = append(append([]TransactionDebuggerOption{}, entry.Options...), WithRegisterGetWrappers(a.override))
+		runs = append(runs, original, upgraded)
+	}
+
+	results := a.batch.Run(ctx, runs)
+
+	report := &UpgradeReport{
+		Transactions: len(entries),
+	}
+	for i, name := range names {
+		original := results[2*i]
+		upgraded := results[2*i+1]
+
+		if original.Result == nil || upgraded.Result == nil {
+			if report.Errors == nil {
+				report.Errors = make(map[string]string)
+			}
+			report.Errors[name] = original.Error
+			if original.Result != nil {
+				report.Errors[name] = upgraded.Error
+			}
+			continue
+		}
+
+		diff := diffUpgrade(original.Result, upgraded.Result)
+		diff.Name = name
+		if diff.Changed() {
+			report.Changed++
+		}
+		report.Diffs = append(report.Diffs, diff)
+	}
+	return report
+}
+
+func diffUpgrade(original *RunResult, upgraded *RunResult) UpgradeDiff {
+	diff := UpgradeDiff{
+		TransactionID: original.TransactionID,
+		BlockHeight:   original.BlockHeight,
+		Original:      newUpgradeOutcome(original),
+		Upgraded:      newUpgradeOutcome(upgraded),
+	}
+	diff.StatusChanged = diff.Original.Status != diff.Upgraded.Status || diff.Original.Error != diff.Upgraded.Error
+	diff.ComputationDelta = int64(diff.Upgraded.ComputationUsed) - int64(diff.Original.ComputationUsed)
+
+	for i := 0; i < len(original.Events) || i < len(upgraded.Events); i++ {
+		var originalEvent, upgradedEvent *RunEvent
+		if i < len(original.Events) {
+			originalEvent = &original.Events[i]
+		}
+		if i < len(upgraded.Events) {
+			upgradedEvent = &upgraded.Events[i]
+		}
+		if originalEvent != nil && upgradedEvent != nil && *originalEvent == *upgradedEvent {
+			continue
+		}
+		diff.Events = append(diff.Events, EventDiff{
+			Index:    i,
+			Original: originalEvent,
+			Upgraded: upgradedEvent,
+		})
+	}
+
+	diff.Registers = diffRegisterWrites(original.RegistersWritten, upgraded.RegistersWritten)
+	return diff
+}
+
+func newUpgradeOutcome(result *RunResult) *UpgradeOutcome {
+	outcome := &UpgradeOutcome{
+		Status:    result.Status,
+		Directory: result.Directory,
+	}
+	if result.Error != nil {
+		outcome.Error = result.Error.Message
+	}
+	if result.Metering != nil {
+		outcome.ComputationUsed = result.Metering.ComputationUsed
+	}
+	return outcome
+}
+
+// diffRegisterWrites returns the registers written with different values, sorted by owner and key.
+func diffRegisterWrites(before []RegisterWrite, after []RegisterWrite) []RegisterDiff {
+	type registerValues struct {
+		before string
+		after  string
+	}
+
+	values := make(map[registers.RegisterKey]*registerValues)
+	for _, write := range before {
+		values[registers.RegisterKey{Owner: write.Owner, Key: write.Key}] = &registerValues{before: write.Value}
+	}
+	for _, write := range after {
+		key := registers.RegisterKey{Owner: write.Owner, Key: write.Key}
+		if v, ok := values[key]; ok {
+			v.after = write.Value
+		} else {
+			values[key] = &registerValues{after: write.Value}
+		}
+	}
+
+	var diffs []RegisterDiff
+	for key, v := range values {
+		if v.before == v.after {
+			continue
+		}
+		diffs = append(diffs, RegisterDiff{
+			Owner:  key.Owner,
+			Key:    key.Key,
+			Before: v.before,
+			After:  v.after,
+		})
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Owner != diffs[j].Owner {
+			return diffs[i].Owner < diffs[j].Owner
+		}
+		return diffs[i].Key < diffs[j].Key
+	})
+	return diffs
+}
+
+// WriteReport writes the table of the changed transactions to w and the full report as JSON to the batch directory.
+func (a *UpgradeAnalyzer) WriteReport(report *UpgradeReport, w io.Writer) error {
+	_, _ = fmt.Fprintf(w, "%d of %d transactions changed with the upgraded code\n", report.Changed, report.Transactions)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "Name\tOriginal\tUpgraded\tEvents\tRegisters\tComputation")
+	for _, diff := range report.Diffs {
+		if !diff.Changed() && diff.ComputationDelta == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%+d\n",
+			diff.Name, diff.Original.Status, diff.Upgraded.Status, len(diff.Events), len(diff.Registers), diff.ComputationDelta)
+	}
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+	if len(report.Errors) > 0 {
+		_, _ = fmt.Fprintf(w, "%d transactions could not be run\n", len(report.Errors))
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	filename := filepath.Join(a.batch.directory, upgradeReportFilename)
+	err = os.MkdirAll(filepath.Dir(filename), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
@@ -0,0 +1,62 @@
+package debuggers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffRegisterWrites(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after []RegisterWrite
+		diffs         []RegisterDiff
+	}{
+		{name: "no writes"},
+		{
+			name:   "same writes",
+			before: []RegisterWrite{{Owner: "01", Key: "balance", Value: "0a"}},
+			after:  []RegisterWrite{{Owner: "01", Key: "balance", Value: "0a"}},
+		},
+		{
+			name:   "changed value",
+			before: []RegisterWrite{{Owner: "01", Key: "balance", Value: "0a"}},
+			after:  []RegisterWrite{{Owner: "01", Key: "balance", Value: "0b"}},
+			diffs:  []RegisterDiff{{Owner: "01", Key: "balance", Before: "0a", After: "0b"}},
+		},
+		{
+			name:   "written only before",
+			before: []RegisterWrite{{Owner: "01", Key: "balance", Value: "0a"}},
+			diffs:  []RegisterDiff{{Owner: "01", Key: "balance", Before: "0a"}},
+		},
+		{
+			name:  "written only after",
+			after: []RegisterWrite{{Owner: "01", Key: "balance", Value: "0b"}},
+			diffs: []RegisterDiff{{Owner: "01", Key: "balance", After: "0b"}},
+		},
+		{
+			name: "sorted by owner and key",
+			before: []RegisterWrite{
+				{Owner: "02", Key: "a", Value: "01"},
+				{Owner: "01", Key: "b", Value: "01"},
+				{Owner: "01", Key: "same", Value: "01"},
+			},
+			after: []RegisterWrite{
+				{Owner: "01", Key: "same", Value: "01"},
+				{Owner: "01", Key: "a", Value: "02"},
+			},
+			diffs: []RegisterDiff{
+				{Owner: "01", Key: "a", After: "02"},
+				{Owner: "01", Key: "b", Before: "01"},
+				{Owner: "02", Key: "a", Before: "01"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs := diffRegisterWrites(tt.before, tt.after)
+			if !reflect.DeepEqual(diffs, tt.diffs) {
+				t.Errorf("diffs are %v, expected %v", diffs, tt.diffs)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package debugger
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+	"strconv"
+)
+
+var _ TransactionResolver = &ExecutionHeightOverride{}
+var _ ChangeDescriber = &ExecutionHeightOverride{}
+var _ TransactionIdentifier = &ExecutionHeightOverride{}
+
+// ExecutionHeightOverride decorates a transaction resolver to execute the transaction
+// against the state (and block header) at a different height than the one it was originally executed at,
+// e.g. to check whether a contract upgrade would have fixed a failed transaction.
+type ExecutionHeightOverride struct {
+	Resolver TransactionResolver
+	Height   uint64
+}
+
+func (e *ExecutionHeightOverride) TransactionBody() (*flow.TransactionBody, error) {
+	return e.Resolver.TransactionBody()
+}
+
+func (e *ExecutionHeightOverride) BlockHeight() (uint64, error) {
+	return e.Height, nil
+}
+
+// ImpersonatedAccounts passes through the impersonated accounts of the decorated resolver.
+func (e *ExecutionHeightOverride) ImpersonatedAccounts() ([]flow.Address, error) {
+	if impersonator, ok := e.Resolver.(Impersonator); ok {
+		return impersonator.ImpersonatedAccounts()
+	}
+	return nil, nil
+}
+
+// TransactionID returns the network ID of the decorated resolver, the transaction itself is not changed.
+func (e *ExecutionHeightOverride) TransactionID() (flow.Identifier, error) {
+	if identifier, ok := e.Resolver.(TransactionIdentifier); ok {
+		return identifier.TransactionID()
+	}
+	txBody, err := e.Resolver.TransactionBody()
+	if err != nil {
+		return flow.ZeroID, err
+	}
+	return txBody.ID(), nil
+}
+
+func (e *ExecutionHeightOverride) Changes() ([]TransactionChange, error) {
+	var changes []TransactionChange
+	if describer, ok := e.Resolver.(ChangeDescriber); ok {
+		var err error
+		changes, err = describer.Changes()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	original, err := e.Resolver.BlockHeight()
+	if err != nil {
+		return nil, err
+	}
+	if original == e.Height {
+		return changes, nil
+	}
+
+	return append(changes, TransactionChange{
+		Field: "blockHeight",
+		From:  strconv.FormatUint(original, 10),
+		To:    strconv.FormatUint(e.Height, 10),
+	}), nil
+}
@@ -0,0 +1,37 @@
+package debugger
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+	"testing"
+)
+
+func TestExecutionHeightOverrideTransactionID(t *testing.T) {
+	txBody := flow.NewTransactionBody().SetScript([]byte("transaction {}"))
+	networkID := flow.Identifier{1, 2, 3}
+
+	tests := []struct {
+		name     string
+		resolver TransactionResolver
+		expected flow.Identifier
+	}{
+		{name: "network transaction", resolver: &NetworkTransactions{ID: networkID}, expected: networkID},
+		{
+			name:     "overridden network transaction",
+			resolver: &OverriddenTransaction{Resolver: &NetworkTransactions{ID: networkID}, GasLimit: 200},
+			expected: networkID,
+		},
+		{name: "custom transaction", resolver: &CustomTransaction{Tx: txBody}, expected: txBody.ID()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			override := &ExecutionHeightOverride{Resolver: tt.resolver, Height: 10}
+			txID, err := override.TransactionID()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if txID != tt.expected {
+				t.Errorf("transaction ID is %s, expected %s", txID, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package debugger
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	sdk "github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultGasLimit is used for locally authored transactions that do not specify a gas limit.
+const DefaultGasLimit = 9999
+
+var _ TransactionResolver = &FileTransaction{}
+var _ Impersonator = &FileTransaction{}
+
+// FileTransaction implements transaction resolver that loads a transaction from disk.
+// The file can be:
+//   - a JSON transaction manifest (see TransactionManifest) referencing a .cdc script,
+//   - a JSON encoded flow.TransactionBody,
+//   - an RLP encoded transaction (raw or hex), with the .rlp extension.
+//
+// Height overrides the height from the file, it is required for encoded transaction bodies.
+type FileTransaction struct {
+	Path   string
+	Height uint64
+
+	tx          *flow.TransactionBody
+	height      uint64
+	impersonate bool
+}
+
+// TransactionManifest describes a locally authored transaction.
+type TransactionManifest struct {
+	// ScriptFile is the path to the .cdc transaction code, relative to the manifest.
+	ScriptFile string `json:"scriptFile"`
+	// Arguments are JSON-Cadence encoded values.
+	Arguments   []json.RawMessage `json:"arguments"`
+	Proposer    *ManifestProposal `json:"proposer"`
+	Payer       string            `json:"payer"`
+	Authorizers []string          `json:"authorizers"`
+	GasLimit    uint64            `json:"gasLimit"`
+	// Height is the block height whose state the transaction is executed against.
+	Height           uint64 `json:"height"`
+	ReferenceBlockID string `json:"referenceBlockId"`
+	// Impersonate lets the proposer, payer and authorizers act without signatures,
+	// otherwise signatures and sequence numbers are not checked at all.
+	Impersonate bool `json:"impersonate"`
+}
+
+type ManifestProposal struct {
+	Address        string `json:"address"`
+	KeyIndex       uint64 `json:"keyIndex"`
+	SequenceNumber uint64 `json:"sequenceNumber"`
+}
+
+func (f *FileTransaction) TransactionBody() (*flow.TransactionBody, error) {
+	err := f.load()
+	if err != nil {
+		return nil, err
+	}
+	return f.tx, nil
+}
+
+func (f *FileTransaction) BlockHeight() (uint64, error) {
+	err := f.load()
+	if err != nil {
+		return 0, err
+	}
+	if f.Height != 0 {
+		return f.Height, nil
+	}
+	if f.height == 0 {
+		return 0, fmt.Errorf("no block height given for transaction file %s", f.Path)
+	}
+	return f.height, nil
+}
+
+func (f *FileTransaction) load() error {
+	if f.tx != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return errors.Wrap(err, "failed to read transaction file")
+	}
+
+	switch strings.ToLower(filepath.Ext(f.Path)) {
+	case ".rlp":
+		f.tx, err = decodeRLPTransaction(data)
+	case ".json":
+		f.tx, f.height, f.impersonate, err = f.decodeJSON(data)
+	default:
+		err = fmt.Errorf("unsupported transaction file extension %s, expected .json or .rlp", filepath.Ext(f.Path))
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed decoding transaction file %s", f.Path)
+	}
+	return nil
+}
+
+// decodeJSON decodes either a transaction manifest or a JSON encoded transaction body.
+func (f *FileTransaction) decodeJSON(data []byte) (*flow.TransactionBody, uint64, bool, error) {
+	var manifest TransactionManifest
+	err := json.Unmarshal(data, &manifest)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	if manifest.ScriptFile == "" {
+		var txBody flow.TransactionBody
+		err := json.Unmarshal(data, &txBody)
+		if err != nil {
+			return nil, 0, false, err
+		}
+		if len(txBody.Script) == 0 {
+			return nil, 0, false, fmt.Errorf("transaction has no script")
+		}
+		return &txBody, 0, false, nil
+	}
+
+	txBody, err := manifest.TransactionBody(filepath.Dir(f.Path))
+	if err != nil {
+		return nil, 0, false, err
+	}
+	return txBody, manifest.Height, manifest.Impersonate, nil
+}
+
+// ImpersonatedAccounts returns the signers of the transaction if the manifest asks to impersonate them.
+func (f *FileTransaction) ImpersonatedAccounts() ([]flow.Address, error) {
+	err := f.load()
+	if err != nil {
+		return nil, err
+	}
+	if !f.impersonate {
+		return nil, nil
+	}
+
+	addresses := []flow.Address{f.tx.ProposalKey.Address, f.tx.Payer}
+	return append(addresses, f.tx.Authorizers...), nil
+}
+
+// TransactionBody builds the transaction described by the manifest,
+// the script file is resolved relative to dir.
+// The proposer and payer default to the first authorizer.
+func (m TransactionManifest) TransactionBody(dir string) (*flow.TransactionBody, error) {
+	scriptFile := m.ScriptFile
+	if !filepath.IsAbs(scriptFile) {
+		scriptFile = filepath.Join(dir, scriptFile)
+	}
+	script, err := os.ReadFile(scriptFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read script file")
+	}
+
+	txBody := flow.NewTransactionBody().
+		SetScript(script).
+		SetGasLimit(m.GasLimit)
+	if m.GasLimit == 0 {
+		txBody.SetGasLimit(DefaultGasLimit)
+	}
+
+	for i, argument := range m.Arguments {
+		// validate the arguments early, the FVM error for a malformed argument is hard to read
+		_, err := jsoncdc.Decode(nil, argument)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid JSON-Cadence argument %d", i)
+		}
+		txBody.AddArgument(argument)
+	}
+
+	for _, authorizer := range m.Authorizers {
+		address, err := ParseAddress(authorizer)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid authorizer")
+		}
+		txBody.AddAuthorizer(address)
+	}
+
+	var defaultAddress flow.Address
+	if len(txBody.Authorizers) > 0 {
+		defaultAddress = txBody.Authorizers[0]
+	}
+
+	payer := defaultAddress
+	if m.Payer != "" {
+		payer, err = ParseAddress(m.Payer)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid payer")
+		}
+	}
+	txBody.SetPayer(payer)
+
+	proposer := flow.ProposalKey{Address: payer}
+	if m.Proposer != nil {
+		proposer.Address, err = ParseAddress(m.Proposer.Address)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid proposer")
+		}
+		proposer.KeyIndex = m.Proposer.KeyIndex
+		proposer.SequenceNumber = m.Proposer.SequenceNumber
+	}
+	txBody.SetProposalKey(proposer.Address, proposer.KeyIndex, proposer.SequenceNumber)
+
+	if m.ReferenceBlockID != "" {
+		referenceBlockID, err := flow.HexStringToIdentifier(m.ReferenceBlockID)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid reference block ID")
+		}
+		txBody.SetReferenceBlockID(referenceBlockID)
+	}
+
+	return txBody, nil
+}
+
+// decodeRLPTransaction decodes a transaction encoded the same way as the Flow SDK and CLI do,
+// either raw or as a hex string.
+func decodeRLPTransaction(data []byte) (*flow.TransactionBody, error) {
+	trimmed := bytes.TrimSpace(data)
+	if decoded, err := hex.DecodeString(strings.TrimPrefix(string(trimmed), "0x")); err == nil {
+		data = decoded
+	}
+
+	tx, err := sdk.DecodeTransaction(data)
+	if err != nil {
+		return nil, err
+	}
+
+	txBody := flow.NewTransactionBody().
+		SetScript(tx.Script).
+		SetReferenceBlockID(flow.Identifier(tx.ReferenceBlockID)).
+		SetGasLimit(tx.GasLimit).
+		SetProposalKey(
+			flow.Address(tx.ProposalKey.Address),
+			uint64(tx.ProposalKey.KeyIndex),
+			tx.ProposalKey.SequenceNumber,
+		).
+		SetPayer(flow.Address(tx.Payer))
+
+	for _, argument := range tx.Arguments {
+		txBody.AddArgument(argument)
+	}
+	for _, authorizer := range tx.Authorizers {
+		txBody.AddAuthorizer(flow.Address(authorizer))
+	}
+	for _, sig := range tx.PayloadSignatures {
+		txBody.AddPayloadSignature(flow.Address(sig.Address), uint64(sig.KeyIndex), sig.Signature)
+	}
+	for _, sig := range tx.EnvelopeSignatures {
+		txBody.AddEnvelopeSignature(flow.Address(sig.Address), uint64(sig.KeyIndex), sig.Signature)
+	}
+
+	return txBody, nil
+}
+
+// ParseAddress decodes a hex encoded address, with or without the 0x prefix.
+func ParseAddress(address string) (flow.Address, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(address, "0x"))
+	if err != nil {
+		return flow.EmptyAddress, err
+	}
+	if len(b) > flow.AddressLength {
+		return flow.EmptyAddress, fmt.Errorf("address %s is too long", address)
+	}
+	return flow.BytesToAddress(b), nil
+}
@@ -7,6 +7,9 @@ require (
 	github.com/onflow/cadence v0.28.1-0.20221223171403-ac91356b44aa
 	github.com/onflow/flow-dps v1.3.4-0.20220831153436-e9e0f57d6ce1
 	github.com/onflow/flow-go v0.28.17-0.20221223175550-80a861fffa6d
+	github.com/onflow/flow-go-sdk v0.29.0
+	github.com/onflow/flow/protobuf/go/flow v0.3.1
+	github.com/pkg/errors v0.9.1
 	github.com/rs/zerolog v1.28.0
 	google.golang.org/grpc v1.47.0
 )
@@ -78,14 +81,11 @@ require (
 	github.com/onflow/flow-core-contracts/lib/go/contracts v0.11.2-0.20220720151516-797b149ceaaa // indirect
 	github.com/onflow/flow-core-contracts/lib/go/templates v0.11.2-0.20220720151516-797b149ceaaa // indirect
 	github.com/onflow/flow-ft/lib/go/contracts v0.5.0 // indirect
-	github.com/onflow/flow-go-sdk v0.29.0 // indirect
 	github.com/onflow/flow-go/crypto v0.24.4 // indirect
-	github.com/onflow/flow/protobuf/go/flow v0.3.1 // indirect
 	github.com/onflow/sdks v0.4.4 // indirect
 	github.com/pbnjay/memory v0.0.0-20210728143218-7b4eea64cf58 // indirect
 	github.com/pelletier/go-toml v1.9.5 // indirect
 	github.com/pelletier/go-toml/v2 v2.0.2 // indirect
-	github.com/pkg/errors v0.9.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/prometheus/client_golang v1.12.1 // indirect
 	github.com/prometheus/client_model v0.2.0 // indirect
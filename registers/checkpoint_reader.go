@@ -0,0 +1,178 @@
+package registers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/ledger/complete/mtrie"
+	"github.com/onflow/flow-go/ledger/complete/mtrie/trie"
+	"github.com/onflow/flow-go/ledger/complete/wal"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow-go/module/metrics"
+	"github.com/rs/zerolog"
+)
+
+// checkpointForestCapacity is the number of tries kept in memory while replaying WAL segments.
+const checkpointForestCapacity = 1000
+
+// errTargetCommitReached stops the WAL replay once the requested state is built.
+var errTargetCommitReached = errors.New("target state commitment reached")
+
+// CheckpointLedger holds the execution state loaded from an execution node checkpoint file on disk,
+// optionally brought forward by replaying WAL segments.
+// It allows reading registers without any archive service.
+type CheckpointLedger struct {
+	forest *mtrie.Forest
+	latest flow.StateCommitment
+
+	log zerolog.Logger
+}
+
+// LoadCheckpointLedger loads the checkpoint file and, if walDir is not empty, replays the WAL segments
+// in it on top of the checkpoint. The replay stops when the target state commitment is reached,
+// if target is empty all segments are replayed.
+func LoadCheckpointLedger(
+	checkpointFile string,
+	walDir string,
+	target flow.StateCommitment,
+	log zerolog.Logger,
+) (*CheckpointLedger, error) {
+	forest, err := mtrie.NewForest(checkpointForestCapacity, metrics.NewNoopCollector(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create forest: %w", err)
+	}
+
+	c := &CheckpointLedger{
+		forest: forest,
+		log:    log,
+	}
+
+	log.Info().Msgf("loading checkpoint file: %s", checkpointFile)
+	tries, err := wal.LoadCheckpoint(checkpointFile, &log)
+	if err != nil {
+		return nil, fmt.Errorf("could not load checkpoint: %w", err)
+	}
+	err = c.addTries(tries)
+	if err != nil {
+		return nil, err
+	}
+
+	if walDir != "" && !c.HasCommit(target) {
+		err = c.replay(walDir, target)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if target != (flow.StateCommitment{}) && !c.HasCommit(target) {
+		return nil, fmt.Errorf("state commitment %x not found in checkpoint or WAL", target[:])
+	}
+
+	return c, nil
+}
+
+// replay applies the WAL segments in walDir that build on the loaded tries.
+func (c *CheckpointLedger) replay(walDir string, target flow.StateCommitment) error {
+	diskWAL, err := wal.NewDiskWAL(
+		c.log,
+		nil,
+		metrics.NewNoopCollector(),
+		walDir,
+		checkpointForestCapacity,
+		pathfinder.PathByteSize,
+		wal.SegmentSize,
+	)
+	if err != nil {
+		return fmt.Errorf("could not open WAL: %w", err)
+	}
+
+	updates := 0
+	err = diskWAL.ReplayLogsOnly(
+		c.addTries,
+		func(update *ledger.TrieUpdate) error {
+			if !c.forest.HasTrie(update.RootHash) {
+				// the update builds on a state older than the checkpoint
+				return nil
+			}
+			rootHash, err := c.forest.Update(update)
+			if err != nil {
+				return fmt.Errorf("could not apply WAL update: %w", err)
+			}
+			updates++
+			c.latest = flow.StateCommitment(rootHash)
+			if c.latest == target {
+				return errTargetCommitReached
+			}
+			return nil
+		},
+		func(ledger.RootHash) error {
+			// deletions only free memory on execution nodes
+			return nil
+		},
+	)
+	if err != nil && !errors.Is(err, errTargetCommitReached) {
+		return fmt.Errorf("could not replay WAL: %w", err)
+	}
+
+	c.log.Info().
+		Int("updates", updates).
+		Hex("commit", c.latest[:]).
+		Msg("replayed WAL segments")
+	return nil
+}
+
+func (c *CheckpointLedger) addTries(tries []*trie.MTrie) error {
+	err := c.forest.AddTries(tries)
+	if err != nil {
+		return fmt.Errorf("could not add checkpoint tries: %w", err)
+	}
+	if len(tries) > 0 {
+		c.latest = flow.StateCommitment(tries[len(tries)-1].RootHash())
+	}
+	return nil
+}
+
+// HasCommit returns true if the state with the given commitment is loaded.
+func (c *CheckpointLedger) HasCommit(commit flow.StateCommitment) bool {
+	return c.forest.HasTrie(ledger.RootHash(commit))
+}
+
+// LatestCommit returns the commitment of the last state loaded from the checkpoint or the WAL.
+func (c *CheckpointLedger) LatestCommit() flow.StateCommitment {
+	return c.latest
+}
+
+// Reader returns a register reader over the state with the given commitment.
+func (c *CheckpointLedger) Reader(commit flow.StateCommitment) RegisterGetRegisterFunc {
+	return func(owner string, key string) (flow.RegisterValue, error) {
+		ledgerKey := state.RegisterIDToKey(flow.RegisterID{Key: key, Owner: owner})
+		ledgerPath, err := pathfinder.KeyToPath(ledgerKey, complete.DefaultPathFinderVersion)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := c.forest.ReadSingleValue(&ledger.TrieReadSingleValue{
+			RootHash: ledger.RootHash(commit),
+			Path:     ledgerPath,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return flow.RegisterValue(value), nil
+	}
+}
+
+// ReaderFactory returns a factory that reads the state with the given commitment,
+// the checkpoint does not know about heights so the requested block height is ignored.
+func (c *CheckpointLedger) ReaderFactory(commit flow.StateCommitment) RegisterReaderFactory {
+	return func(blockHeight uint64) (RegisterGetRegisterFunc, error) {
+		c.log.Info().
+			Uint64("height", blockHeight).
+			Hex("commit", commit[:]).
+			Msg("reading registers from local checkpoint")
+		return c.Reader(commit), nil
+	}
+}
@@ -0,0 +1,194 @@
+package registers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+const (
+	goroutines = 16
+	iterations = 100
+)
+
+// concurrently calls fn from many goroutines at the same time.
+func concurrently(fn func(g int, i int)) {
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				fn(g, i)
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func owner(i int) string {
+	return string(flow.HexToAddress(fmt.Sprintf("%x", i+1)).Bytes())
+}
+
+// countingReader returns the key as the value and counts the reads.
+func countingReader(reads *int64) RegisterGetRegisterFunc {
+	return func(owner string, key string) (flow.RegisterValue, error) {
+		atomic.AddInt64(reads, 1)
+		return flow.RegisterValue(key), nil
+	}
+}
+
+// inTempDir runs the test in a temporary working directory, the file cache writes to the working directory.
+func inTempDir(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chdir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+}
+
+func TestRemoteRegisterFileCacheConcurrentReads(t *testing.T) {
+	inTempDir(t)
+
+	cache, err := NewRemoteRegisterFileCache(7, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reads int64
+	read := countingReader(&reads)
+	read.Wrap(cache)
+
+	concurrently(func(g int, i int) {
+		key := fmt.Sprintf("key%d", i%10)
+		value, err := read(owner(i%3), key)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if string(value) != key {
+			t.Errorf("value is %q, expected %q", value, key)
+		}
+	})
+
+	// concurrent misses may read a register more than once, but most reads must be hits
+	if reads < 30 || reads > 30*goroutines {
+		t.Errorf("%d reads for 30 registers", reads)
+	}
+
+	err = cache.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewRemoteRegisterFileCache(7, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	reads = 0
+	read = countingReader(&reads)
+	read.Wrap(reopened)
+	for i := 0; i < 30; i++ {
+		_, err := read(owner(i%3), fmt.Sprintf("key%d", i%10))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if reads != 0 {
+		t.Errorf("%d registers were not persisted", reads)
+	}
+}
+
+func TestRemoteRegisterReadTrackerConcurrentReads(t *testing.T) {
+	directory := t.TempDir()
+	tracker := NewRemoteRegisterReadTracker(directory, zerolog.Nop())
+
+	var reads int64
+	read := countingReader(&reads)
+	read.Wrap(tracker)
+
+	concurrently(func(g int, i int) {
+		_, err := read(owner(g), fmt.Sprintf("key%d", i%5))
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	keys := tracker.RegistersRead()
+	if len(keys) != goroutines*5 {
+		t.Errorf("%d registers read, expected %d", len(keys), goroutines*5)
+	}
+
+	err := tracker.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(filepath.Join(directory, "registers_read.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	lines, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 1+goroutines*iterations {
+		t.Errorf("%d lines, expected a header and %d reads", len(lines), goroutines*iterations)
+	}
+}
+
+func TestCaptureContractWrapperConcurrentReads(t *testing.T) {
+	directory := t.TempDir()
+	capture := NewCaptureContractWrapper(directory, zerolog.Nop())
+
+	var reads int64
+	read := countingReader(&reads)
+	read.Wrap(capture)
+
+	concurrently(func(g int, i int) {
+		key := "storage"
+		if i%2 == 0 {
+			key = fmt.Sprintf("code.Contract%d", i%4)
+		}
+		_, err := read(owner(g%4), key)
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	err := capture.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for o := 0; o < 4; o++ {
+		address := flow.BytesToAddress([]byte(owner(o))).HexWithPrefix()
+		for _, name := range []string{"Contract0", "Contract2"} {
+			code, err := os.ReadFile(filepath.Join(directory, address, name+".cdc"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(code) != "code."+name {
+				t.Errorf("code of %s is %q", name, code)
+			}
+		}
+	}
+	files, err := filepath.Glob(filepath.Join(directory, "*", "*.cdc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 8 {
+		t.Errorf("%d contracts captured, expected 8", len(files))
+	}
+}
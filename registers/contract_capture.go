@@ -6,9 +6,13 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
+// CaptureContractWrapper captures the code of the contracts read by a run.
+// It is safe for concurrent use, but it should not be shared between runs.
 type CaptureContractWrapper struct {
+	mu        sync.Mutex
 	contracts map[string]map[string]string
 	directory string
 
@@ -36,11 +40,13 @@ func (c *CaptureContractWrapper) Wrap(inner RegisterGetRegisterFunc) RegisterGet
 			address := flow.BytesToAddress([]byte(owner)).HexWithPrefix()
 			contractName := strings.TrimPrefix(key, "code.")
 
+			c.mu.Lock()
 			if _, ok := c.contracts[address]; !ok {
 				c.contracts[address] = make(map[string]string)
 			}
 
 			c.contracts[address][contractName] = string(val)
+			c.mu.Unlock()
 		}
 
 		return val, nil
@@ -48,6 +54,9 @@ func (c *CaptureContractWrapper) Wrap(inner RegisterGetRegisterFunc) RegisterGet
 }
 
 func (c *CaptureContractWrapper) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	for account, contracts := range c.contracts {
 		for name, code := range contracts {
 			filename := filepath.Join(c.directory, account, name+".cdc")
@@ -55,11 +64,7 @@ func (c *CaptureContractWrapper) Close() error {
 			if err != nil {
 				return err
 			}
-			file, err := os.Create(filename)
-			if err != nil {
-				return err
-			}
-			_, err = file.WriteString(code)
+			err = os.WriteFile(filename, []byte(code), 0644)
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,44 @@
+package registers
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+	"sync"
+)
+
+// ContractCodeOverride replaces the code of deployed contracts.
+// Only the code register is replaced, so the contract must already be deployed on the account.
+// It is safe for concurrent use and can be shared between runs.
+type ContractCodeOverride struct {
+	mu    sync.RWMutex
+	codes map[RegisterKey][]byte
+}
+
+var _ RegisterGetWrapper = &ContractCodeOverride{}
+
+func NewContractCodeOverride() *ContractCodeOverride {
+	return &ContractCodeOverride{
+		codes: make(map[RegisterKey][]byte),
+	}
+}
+
+// Set replaces the code of the contract with the given name on the account.
+func (c *ContractCodeOverride) Set(address flow.Address, name string, code []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := RegisterKey{Owner: string(address.Bytes()), Key: "code." + name}
+	c.codes[key] = code
+}
+
+func (c *ContractCodeOverride) Wrap(inner RegisterGetRegisterFunc) RegisterGetRegisterFunc {
+	return func(owner string, key string) (flow.RegisterValue, error) {
+		c.mu.RLock()
+		code, ok := c.codes[RegisterKey{Owner: owner, Key: key}]
+		c.mu.RUnlock()
+		if ok {
+			return code, nil
+		}
+
+		return inner(owner, key)
+	}
+}
@@ -0,0 +1,44 @@
+package registers
+
+import (
+	"context"
+	"fmt"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/execution"
+)
+
+// NewExecutionNodeReader reads registers at the given block using the execution API.
+// Access nodes do not serve register values, so the client has to point to an execution node
+// (or a proxy of one) that still holds the state of the block.
+func NewExecutionNodeReader(client execution.ExecutionAPIClient, blockID flow.Identifier) RegisterGetRegisterFunc {
+	return func(owner string, key string) (flow.RegisterValue, error) {
+		resp, err := client.GetRegisterAtBlockID(context.Background(), &execution.GetRegisterAtBlockIDRequest{
+			BlockId:       blockID[:],
+			RegisterOwner: []byte(owner),
+			RegisterKey:   []byte(key),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return resp.Value, nil
+	}
+}
+
+// NewExecutionNodeReaderFactory creates register readers over the execution API,
+// resolving block heights to block IDs using the access node.
+func NewExecutionNodeReaderFactory(
+	accessClient access.AccessAPIClient,
+	executionClient execution.ExecutionAPIClient,
+) RegisterReaderFactory {
+	return func(blockHeight uint64) (RegisterGetRegisterFunc, error) {
+		resp, err := accessClient.GetBlockHeaderByHeight(context.Background(), &access.GetBlockHeaderByHeightRequest{
+			Height: blockHeight,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not get block at height %d: %w", blockHeight, err)
+		}
+		blockID := flow.HashToID(resp.Block.Id)
+		return NewExecutionNodeReader(executionClient, blockID), nil
+	}
+}
@@ -0,0 +1,131 @@
+package registers
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/onflow/flow-dps/api/dps"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+)
+
+// MultiRemoteReader reads registers from several archive nodes.
+// Reads are spread round-robin over the nodes, a failing node is skipped in favour of the next one,
+// and a sample of the reads can be cross-checked against a second node to catch a stale archive.
+type MultiRemoteReader struct {
+	clients        []dps.APIClient
+	blockHeight    uint64
+	crossCheckRate float64
+
+	next uint64
+
+	randMu sync.Mutex
+	rand   *rand.Rand
+
+	log zerolog.Logger
+}
+
+// NewMultiRemoteReader creates a reader over the given archive clients.
+// crossCheckRate is the fraction of reads (between 0 and 1) that are also read from a second archive node
+// and compared, it has no effect with a single client.
+func NewMultiRemoteReader(
+	clients []dps.APIClient,
+	blockHeight uint64,
+	crossCheckRate float64,
+	log zerolog.Logger,
+) (*MultiRemoteReader, error) {
+	if len(clients) == 0 {
+		return nil, fmt.Errorf("at least one archive client is required")
+	}
+	if crossCheckRate < 0 || crossCheckRate > 1 {
+		return nil, fmt.Errorf("invalid cross check rate: %v, expected a value between 0 and 1", crossCheckRate)
+	}
+
+	return &MultiRemoteReader{
+		clients:        clients,
+		blockHeight:    blockHeight,
+		crossCheckRate: crossCheckRate,
+		rand:           rand.New(rand.NewSource(rand.Int63())),
+		log:            log,
+	}, nil
+}
+
+// RegisterFunc returns the register read function backed by this reader.
+func (m *MultiRemoteReader) RegisterFunc() RegisterGetRegisterFunc {
+	return m.Get
+}
+
+// Get reads the register from the next archive node in line, failing over to the other nodes on error.
+func (m *MultiRemoteReader) Get(owner string, key string) (flow.RegisterValue, error) {
+	start := int(atomic.AddUint64(&m.next, 1) % uint64(len(m.clients)))
+
+	var lastErr error
+	for i := 0; i < len(m.clients); i++ {
+		index := (start + i) % len(m.clients)
+		val, err := readRemoteRegister(m.clients[index], m.blockHeight, owner, key)
+		if err != nil {
+			m.log.Warn().
+				Err(err).
+				Int("archive", index).
+				Str("register", RegisterKey{owner, key}.String()).
+				Msg("Could not read register from archive node, failing over.")
+			lastErr = err
+			continue
+		}
+
+		if m.shouldCrossCheck() {
+			err = m.crossCheck(index, owner, key, val)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return val, nil
+	}
+
+	return nil, fmt.Errorf("could not read register %v from any archive node: %w", RegisterKey{owner, key}, lastErr)
+}
+
+func (m *MultiRemoteReader) shouldCrossCheck() bool {
+	if len(m.clients) < 2 || m.crossCheckRate == 0 {
+		return false
+	}
+
+	m.randMu.Lock()
+	defer m.randMu.Unlock()
+	return m.rand.Float64() < m.crossCheckRate
+}
+
+// crossCheck reads the register from the archive node following the one that served the read
+// and returns an error if the values differ.
+func (m *MultiRemoteReader) crossCheck(served int, owner string, key string, val flow.RegisterValue) error {
+	index := (served + 1) % len(m.clients)
+	other, err := readRemoteRegister(m.clients[index], m.blockHeight, owner, key)
+	if err != nil {
+		// an unavailable node cannot tell us anything about staleness
+		m.log.Warn().
+			Err(err).
+			Int("archive", index).
+			Msg("Could not cross check register read.")
+		return nil
+	}
+
+	if !bytes.Equal(val, other) {
+		m.log.Error().
+			Int("archive", served).
+			Int("other_archive", index).
+			Uint64("height", m.blockHeight).
+			Str("register", RegisterKey{owner, key}.String()).
+			Msg("Archive nodes returned different register values.")
+		return fmt.Errorf(
+			"archive nodes %d and %d disagree on register %v at height %d, one of them might be stale",
+			served,
+			index,
+			RegisterKey{owner, key},
+			m.blockHeight,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package registers
+
+import (
+	"fmt"
+	"github.com/onflow/flow-go/model/flow"
+	"path/filepath"
+)
+
+// NewOfflineReaderFactory reads registers only from the register cache files in the directory,
+// as written by RemoteRegisterFileCache, so recorded runs can be replayed without network access.
+// Reading a register that is not in the file of the height is an error.
+func NewOfflineReaderFactory(directory string) RegisterReaderFactory {
+	return func(blockHeight uint64) (RegisterGetRegisterFunc, error) {
+		filename := filepath.Join(directory, registerCacheFilename(blockHeight))
+		registers, err := readRegisterFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("could not read offline registers of height %d: %w", blockHeight, err)
+		}
+
+		return func(owner string, key string) (flow.RegisterValue, error) {
+			val, ok := registers[RegisterKey{owner, key}]
+			if !ok {
+				return nil, fmt.Errorf("register %s is not in the offline registers of height %d", RegisterKey{owner, key}, blockHeight)
+			}
+			return val, nil
+		}, nil
+	}
+}
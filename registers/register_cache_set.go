@@ -0,0 +1,56 @@
+package registers
+
+import (
+	"github.com/rs/zerolog"
+	"sync"
+)
+
+// RegisterCacheSet holds one register file cache per block height,
+// so the caches can be shared by many runs.
+// The caches are closed, and written to disk, when the set is closed.
+type RegisterCacheSet struct {
+	mu     sync.Mutex
+	caches map[uint64]*RemoteRegisterFileCache
+
+	log zerolog.Logger
+}
+
+func NewRegisterCacheSet(log zerolog.Logger) *RegisterCacheSet {
+	return &RegisterCacheSet{
+		caches: make(map[uint64]*RemoteRegisterFileCache),
+		log:    log,
+	}
+}
+
+// Cache returns the cache of the given height, opening it on first use.
+func (s *RegisterCacheSet) Cache(blockHeight uint64) (*RemoteRegisterFileCache, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cache, ok := s.caches[blockHeight]
+	if ok {
+		return cache, nil
+	}
+
+	cache, err := NewRemoteRegisterFileCache(blockHeight, s.log)
+	if err != nil {
+		return nil, err
+	}
+	s.caches[blockHeight] = cache
+	return cache, nil
+}
+
+func (s *RegisterCacheSet) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var firstErr error
+	for height, cache := range s.caches {
+		err := cache.Close()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(s.caches, height)
+	}
+	return firstErr
+}
@@ -21,21 +21,29 @@ type RegisterGetWrapper interface {
 	Wrap(RegisterGetRegisterFunc) RegisterGetRegisterFunc
 }
 
+// RegisterReaderFactory creates a register reader for the state at the given block height.
+type RegisterReaderFactory func(blockHeight uint64) (RegisterGetRegisterFunc, error)
+
 func NewRemoteReader(client dps.APIClient, blockHeight uint64) RegisterGetRegisterFunc {
-	return func(key string, address string) (flow.RegisterValue, error) {
-		ledgerKey := state.RegisterIDToKey(flow.RegisterID{Key: key, Owner: address})
-		ledgerPath, err := pathfinder.KeyToPath(ledgerKey, complete.DefaultPathFinderVersion)
-		if err != nil {
-			return nil, err
-		}
-
-		resp, err := client.GetRegisterValues(context.Background(), &dps.GetRegisterValuesRequest{
-			Height: blockHeight,
-			Paths:  [][]byte{ledgerPath[:]},
-		})
-		if err != nil {
-			return nil, err
-		}
-		return resp.Values[0], nil
+	return func(owner string, key string) (flow.RegisterValue, error) {
+		return readRemoteRegister(client, blockHeight, owner, key)
+	}
+}
+
+// readRemoteRegister reads a single register at the given height from the archive node.
+func readRemoteRegister(client dps.APIClient, blockHeight uint64, owner string, key string) (flow.RegisterValue, error) {
+	ledgerKey := state.RegisterIDToKey(flow.RegisterID{Key: key, Owner: owner})
+	ledgerPath, err := pathfinder.KeyToPath(ledgerKey, complete.DefaultPathFinderVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.GetRegisterValues(context.Background(), &dps.GetRegisterValuesRequest{
+		Height: blockHeight,
+		Paths:  [][]byte{ledgerPath[:]},
+	})
+	if err != nil {
+		return nil, err
 	}
+	return resp.Values[0], nil
 }
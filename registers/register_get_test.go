@@ -0,0 +1,55 @@
+package registers
+
+import (
+	"context"
+	"github.com/onflow/flow-dps/api/dps"
+	"github.com/onflow/flow-go/engine/execution/state"
+	"github.com/onflow/flow-go/ledger/common/pathfinder"
+	"github.com/onflow/flow-go/ledger/complete"
+	"github.com/onflow/flow-go/model/flow"
+	"google.golang.org/grpc"
+	"testing"
+)
+
+// pathClient is an archive client that returns the requested path as the register value.
+type pathClient struct {
+	dps.APIClient
+}
+
+func (c *pathClient) GetRegisterValues(
+	_ context.Context,
+	in *dps.GetRegisterValuesRequest,
+	_ ...grpc.CallOption) (*dps.GetRegisterValuesResponse, error) {
+	return &dps.GetRegisterValuesResponse{Values: in.Paths}, nil
+}
+
+func TestRemoteReaderOwnerAndKey(t *testing.T) {
+	owner := string(flow.HexToAddress("1654653399040a61").Bytes())
+	tests := []struct {
+		name  string
+		owner string
+		key   string
+	}{
+		{name: "account register", owner: owner, key: "code.FlowToken"},
+		{name: "global register", owner: "", key: "uuid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := pathfinder.KeyToPath(
+				state.RegisterIDToKey(flow.RegisterID{Owner: tt.owner, Key: tt.key}),
+				complete.DefaultPathFinderVersion,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			value, err := NewRemoteReader(&pathClient{}, 1)(tt.owner, tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(value) != string(path[:]) {
+				t.Errorf("read path %x, expected the path of owner %x and key %s", value, tt.owner, tt.key)
+			}
+		})
+	}
+}
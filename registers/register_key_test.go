@@ -0,0 +1,81 @@
+package registers
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/rs/zerolog"
+	"testing"
+)
+
+func TestRegisterKeyRoundTrip(t *testing.T) {
+	owner := string(flow.HexToAddress("1654653399040a61").Bytes())
+	tests := []struct {
+		name     string
+		key      RegisterKey
+		readable RegisterKey
+	}{
+		{
+			name:     "global register",
+			key:      RegisterKey{"", "uuid"},
+			readable: RegisterKey{"", "uuid"},
+		},
+		{
+			name:     "global account address state",
+			key:      RegisterKey{"", "account_address_state"},
+			readable: RegisterKey{"", "account_address_state"},
+		},
+		{
+			name:     "account register",
+			key:      RegisterKey{owner, "code.FlowToken"},
+			readable: RegisterKey{"1654653399040a61", "code.FlowToken"},
+		},
+		{
+			name:     "slab",
+			key:      RegisterKey{owner, "$\x00\x00\x00\x00\x00\x00\x00\x01"},
+			readable: RegisterKey{"1654653399040a61", "$0000000000000001"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readable := tt.key.ToReadable()
+			if readable != tt.readable {
+				t.Errorf("readable key is %v, expected %v", readable, tt.readable)
+			}
+			if mangled := readable.ToMangled(); mangled != tt.key {
+				t.Errorf("key is %q after the round trip, expected %q", mangled, tt.key)
+			}
+		})
+	}
+}
+
+func TestOfflineReaderGlobalRegisters(t *testing.T) {
+	inTempDir(t)
+
+	cache, err := NewRemoteRegisterFileCache(5, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := RegisterGetRegisterFunc(func(owner string, key string) (flow.RegisterValue, error) {
+		return flow.RegisterValue(key), nil
+	})
+	read.Wrap(cache)
+	_, err = read("", "uuid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cache.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offline, err := NewOfflineReaderFactory(".")(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := offline("", "uuid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(value) != "uuid" {
+		t.Errorf("value is %q", value)
+	}
+}
@@ -0,0 +1,49 @@
+package registers
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+	"sync"
+)
+
+// RegisterReadCounter counts the reads of the registers selected by a filter.
+// It is safe for concurrent use, but it should not be shared between runs.
+type RegisterReadCounter struct {
+	mu    sync.Mutex
+	match func(RegisterKey) bool
+	reads int
+	bytes int
+}
+
+var _ RegisterGetWrapper = &RegisterReadCounter{}
+
+// NewRegisterReadCounter creates a counter of the registers for which match returns true,
+// the keys passed to match are in readable form.
+func NewRegisterReadCounter(match func(RegisterKey) bool) *RegisterReadCounter {
+	return &RegisterReadCounter{
+		match: match,
+	}
+}
+
+func (r *RegisterReadCounter) Wrap(inner RegisterGetRegisterFunc) RegisterGetRegisterFunc {
+	return func(owner string, key string) (flow.RegisterValue, error) {
+		val, err := inner(owner, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if r.match(RegisterKey{owner, key}.ToReadable()) {
+			r.mu.Lock()
+			r.reads++
+			r.bytes += len(val)
+			r.mu.Unlock()
+		}
+		return val, nil
+	}
+}
+
+// Reads returns the number of reads of the matching registers and the bytes read.
+func (r *RegisterReadCounter) Reads() (reads int, bytes int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reads, r.bytes
+}
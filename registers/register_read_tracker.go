@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"sync"
 )
 
 type registerReadEntry struct {
@@ -19,7 +20,10 @@ func (e registerReadEntry) String() string {
 	return fmt.Sprintf("%v: %v bytes", e.key, e.read)
 }
 
+// RemoteRegisterReadTracker records the registers read by a run.
+// It is safe for concurrent use, but it should not be shared between runs.
 type RemoteRegisterReadTracker struct {
+	mu           sync.Mutex
 	registerRead []registerReadEntry
 	filename     string
 
@@ -45,16 +49,38 @@ func (r *RemoteRegisterReadTracker) Wrap(inner RegisterGetRegisterFunc) Register
 			return nil, err
 		}
 
+		r.mu.Lock()
 		r.registerRead = append(r.registerRead, registerReadEntry{
 			key:  k,
 			read: len(val),
 		})
+		r.mu.Unlock()
 
 		return val, nil
 	}
 }
 
+// RegistersRead returns the registers read, in readable form and in the order they were first read.
+func (r *RemoteRegisterReadTracker) RegistersRead() []RegisterKey {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[RegisterKey]struct{}, len(r.registerRead))
+	keys := make([]RegisterKey, 0, len(r.registerRead))
+	for _, read := range r.registerRead {
+		if _, ok := seen[read.key]; ok {
+			continue
+		}
+		seen[read.key] = struct{}{}
+		keys = append(keys, read.key)
+	}
+	return keys
+}
+
 func (r *RemoteRegisterReadTracker) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	err := os.MkdirAll(filepath.Dir(r.filename), os.ModePerm)
 	if err != nil {
 		return err
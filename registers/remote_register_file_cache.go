@@ -7,10 +7,15 @@ import (
 	"github.com/onflow/flow-go/model/flow"
 	"github.com/rs/zerolog"
 	"os"
+	"sync"
 )
 
+// RemoteRegisterFileCache caches the registers of a block height in memory and in a file.
+// It is safe for concurrent use, so one cache can be shared by many runs at the same height.
+// Concurrent misses of the same register may read it more than once.
 type RemoteRegisterFileCache struct {
 	blockHeight uint64
+	mu          sync.RWMutex
 	registers   map[RegisterKey]flow.RegisterValue
 
 	log zerolog.Logger
@@ -36,7 +41,9 @@ func NewRemoteRegisterFileCache(
 
 func (c *RemoteRegisterFileCache) Wrap(registerFunc RegisterGetRegisterFunc) RegisterGetRegisterFunc {
 	return func(owner string, key string) (flow.RegisterValue, error) {
+		c.mu.RLock()
 		val, found := c.registers[RegisterKey{owner, key}]
+		c.mu.RUnlock()
 		if found {
 			return val, nil
 		}
@@ -44,13 +51,18 @@ func (c *RemoteRegisterFileCache) Wrap(registerFunc RegisterGetRegisterFunc) Reg
 		if err != nil {
 			return nil, err
 		}
+		c.mu.Lock()
 		c.registers[RegisterKey{owner, key}] = val
+		c.mu.Unlock()
 		return val, nil
 	}
 }
 
 // Close the cache
 func (c *RemoteRegisterFileCache) Close() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	// overwrite existing file
 	// and dump registers to file as a csv
 	filename := c.getFilename()
@@ -88,7 +100,7 @@ func (c *RemoteRegisterFileCache) open() error {
 
 	c.log.Info().Msgf("opening cache file: %s", filename)
 
-	csvFile, err := os.Open(filename)
+	registers, err := readRegisterFile(filename)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// file does not exist
@@ -97,28 +109,38 @@ func (c *RemoteRegisterFileCache) open() error {
 		}
 		return err
 	}
+	c.registers = registers
+	return nil
+}
+
+// readRegisterFile reads the registers of a cache file, the keys are mangled.
+func readRegisterFile(filename string) (map[RegisterKey]flow.RegisterValue, error) {
+	csvFile, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
 	defer func() { _ = csvFile.Close() }()
 
 	csvLines, err := csv.NewReader(csvFile).ReadAll()
 	if err != nil {
-		return err
+		return nil, err
 	}
+	registers := make(map[RegisterKey]flow.RegisterValue, len(csvLines))
 	for _, line := range csvLines {
 		if len(line) != 3 {
-			return fmt.Errorf("invalid line: %v", line)
+			return nil, fmt.Errorf("invalid line: %v", line)
 		}
 		owner := line[0]
 		key := line[1]
 		value := line[2]
-		decodedValue, err := c.decodeRegisterValue(value)
+		decodedValue, err := hex.DecodeString(value)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		decodedKey := RegisterKey{owner, key}.ToMangled()
-		c.registers[decodedKey] = decodedValue
+		registers[decodedKey] = decodedValue
 	}
-
-	return nil
+	return registers, nil
 }
 
 // encode register value
@@ -126,16 +148,11 @@ func (c *RemoteRegisterFileCache) encodeRegisterValue(value flow.RegisterValue)
 	return hex.EncodeToString(value)
 }
 
-// decode register value
-func (c *RemoteRegisterFileCache) decodeRegisterValue(value string) (flow.RegisterValue, error) {
-	decoded, err := hex.DecodeString(value)
-	if err != nil {
-		return nil, err
-	}
-	return decoded, nil
-}
-
 // getFilename
 func (c *RemoteRegisterFileCache) getFilename() string {
-	return fmt.Sprintf("block-%d-cache.csv", c.blockHeight)
+	return registerCacheFilename(c.blockHeight)
+}
+
+func registerCacheFilename(blockHeight uint64) string {
+	return fmt.Sprintf("block-%d-cache.csv", blockHeight)
 }
@@ -5,26 +5,89 @@ import (
 	"github.com/onflow/execution-debugger/registers"
 	"github.com/onflow/flow-go/fvm/state"
 	"github.com/onflow/flow-go/model/flow"
+	"sort"
+	"sync"
 )
 
+// RemoteView is a view over registers read with the register reader.
+// It is safe for concurrent use.
 type RemoteView struct {
 	Parent *RemoteView
-	Delta  map[string]flow.RegisterValue
+	delta  map[string]flow.RegisterValue
 
+	mu sync.RWMutex
+
+	// registerIDs are the register IDs of the delta keys
+	registerIDs    map[string]flow.RegisterID
 	registerReader registers.RegisterGetRegisterFunc
+
+	// base holds the registers set before the last snapshot or fork, below the delta.
+	// It is never modified, so it is shared with the snapshots and the forks.
+	base *frozenDelta
+	// snapshots are the named states of the view
+	snapshots map[string]*frozenDelta
+}
+
+// frozenDelta is a layer of registers set in a view, on top of the layers below it.
+// Layers are never modified once they are created.
+type frozenDelta struct {
+	below       *frozenDelta
+	delta       map[string]flow.RegisterValue
+	registerIDs map[string]flow.RegisterID
+}
+
+func (f *frozenDelta) get(key string) (flow.RegisterValue, bool) {
+	for layer := f; layer != nil; layer = layer.below {
+		if value, ok := layer.delta[key]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// flatten returns the registers set in all the layers and in the delta on top of them,
+// the register IDs by key and the values by key.
+func (f *frozenDelta) flatten(
+	delta map[string]flow.RegisterValue,
+	registerIDs map[string]flow.RegisterID,
+) (map[string]flow.RegisterValue, map[string]flow.RegisterID) {
+	var layers []*frozenDelta
+	for layer := f; layer != nil; layer = layer.below {
+		layers = append(layers, layer)
+	}
+
+	values := make(map[string]flow.RegisterValue)
+	ids := make(map[string]flow.RegisterID)
+	for i := len(layers) - 1; i >= 0; i-- {
+		for k, value := range layers[i].delta {
+			values[k] = value
+		}
+		for k, id := range layers[i].registerIDs {
+			ids[k] = id
+		}
+	}
+	for k, value := range delta {
+		values[k] = value
+	}
+	for k, id := range registerIDs {
+		ids[k] = id
+	}
+	return values, ids
 }
 
 func NewRemoteView(reader registers.RegisterGetRegisterFunc) *RemoteView {
 	return &RemoteView{
-		Delta:          make(map[string]flow.RegisterValue),
+		delta:          make(map[string]flow.RegisterValue),
+		registerIDs:    make(map[string]flow.RegisterID),
 		registerReader: reader,
 	}
 }
 
 func (v *RemoteView) NewChild() state.View {
 	return &RemoteView{
-		Parent: v,
-		Delta:  make(map[string][]byte),
+		Parent:      v,
+		delta:       make(map[string][]byte),
+		registerIDs: make(map[string]flow.RegisterID),
 	}
 }
 
@@ -35,25 +98,131 @@ func (v *RemoteView) MergeView(o state.View) error {
 		return fmt.Errorf("can not merge: view type mismatch (given: %T, expected:RemoteView)", o)
 	}
 
-	for k, value := range other.Delta {
-		v.Delta[k] = value
+	if other == v {
+		return nil
+	}
+
+	// copy first, so the two views are never locked at the same time
+	other.mu.RLock()
+	values, ids := other.base.flatten(other.delta, other.registerIDs)
+	other.mu.RUnlock()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for k, value := range values {
+		v.delta[k] = value
+	}
+	for k, id := range ids {
+		v.registerIDs[k] = id
 	}
 	return nil
 }
 
 func (v *RemoteView) DropDelta() {
-	v.Delta = make(map[string]flow.RegisterValue)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.base = nil
+	v.delta = make(map[string]flow.RegisterValue)
+	v.registerIDs = make(map[string]flow.RegisterID)
+}
+
+// freeze moves the delta into a new base layer, so the state of the view can be shared without copying it.
+// The caller must hold the write lock.
+func (v *RemoteView) freeze() *frozenDelta {
+	if len(v.delta) == 0 {
+		return v.base
+	}
+	v.base = &frozenDelta{
+		below:       v.base,
+		delta:       v.delta,
+		registerIDs: v.registerIDs,
+	}
+	v.delta = make(map[string]flow.RegisterValue)
+	v.registerIDs = make(map[string]flow.RegisterID)
+	return v.base
+}
+
+// Snapshot saves the registers set in this view under the name, replacing an earlier snapshot with the same name.
+// The parent views are not part of the snapshot.
+// Nothing is copied, the registers set so far are kept in a layer shared by the view and the snapshot.
+func (v *RemoteView) Snapshot(name string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.snapshots == nil {
+		v.snapshots = make(map[string]*frozenDelta)
+	}
+	v.snapshots[name] = v.freeze()
+}
+
+// Rollback restores the registers set in this view to the named snapshot, the snapshot is kept.
+func (v *RemoteView) Rollback(name string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	snapshot, ok := v.snapshots[name]
+	if !ok {
+		return fmt.Errorf("no snapshot named %s", name)
+	}
+	v.base = snapshot
+	v.delta = make(map[string]flow.RegisterValue)
+	v.registerIDs = make(map[string]flow.RegisterID)
+	return nil
+}
+
+// Snapshots returns the names of the snapshots, sorted.
+func (v *RemoteView) Snapshots() []string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	names := make([]string, 0, len(v.snapshots))
+	for name := range v.snapshots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Fork returns a copy-on-write view with the same state as this view.
+// Nothing is copied: the registers set so far are kept in a layer shared by both views,
+// and each view keeps its later writes to itself. The snapshots are not forked.
+// The fork shares the parent and the register reader with this view, so registers already read are not read again
+// if the reader is cached. The fork is independent of this view, but not of the parent:
+// registers set in the parent later on are seen by both views.
+// The parent of the views the debuggers create only holds the state patches, and is not changed after the setup.
+func (v *RemoteView) Fork() *RemoteView {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return &RemoteView{
+		Parent:         v.Parent,
+		delta:          make(map[string]flow.RegisterValue),
+		registerIDs:    make(map[string]flow.RegisterID),
+		registerReader: v.registerReader,
+		base:           v.freeze(),
+	}
 }
 
 func (v *RemoteView) Set(owner, key string, value flow.RegisterValue) error {
-	v.Delta[owner+"~"+key] = value
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.delta[owner+"~"+key] = value
+	v.registerIDs[owner+"~"+key] = flow.RegisterID{Owner: owner, Key: key}
 	return nil
 }
 
 func (v *RemoteView) Get(owner, key string) (flow.RegisterValue, error) {
 
-	// first check the delta
-	value, found := v.Delta[owner+"~"+key]
+	// first check the delta and the layers below it
+	v.mu.RLock()
+	value, found := v.delta[owner+"~"+key]
+	if !found {
+		value, found = v.base.get(owner + "~" + key)
+	}
+	v.mu.RUnlock()
 	if found {
 		return value, nil
 	}
@@ -77,8 +246,29 @@ func (v *RemoteView) AllRegisters() []flow.RegisterID {
 	panic("Not implemented yet")
 }
 
+// RegisterUpdates returns the registers set or deleted in this view, sorted by owner and key.
+// Deleted registers have a nil value.
 func (v *RemoteView) RegisterUpdates() ([]flow.RegisterID, []flow.RegisterValue) {
-	panic("Not implemented yet")
+	v.mu.RLock()
+	values, registerIDs := v.base.flatten(v.delta, v.registerIDs)
+	v.mu.RUnlock()
+
+	ids := make([]flow.RegisterID, 0, len(registerIDs))
+	for _, id := range registerIDs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if ids[i].Owner != ids[j].Owner {
+			return ids[i].Owner < ids[j].Owner
+		}
+		return ids[i].Key < ids[j].Key
+	})
+
+	updates := make([]flow.RegisterValue, 0, len(ids))
+	for _, id := range ids {
+		updates = append(updates, values[id.Owner+"~"+id.Key])
+	}
+	return ids, updates
 }
 
 func (v *RemoteView) Touch(owner, key string) error {
@@ -87,6 +277,10 @@ func (v *RemoteView) Touch(owner, key string) error {
 }
 
 func (v *RemoteView) Delete(owner, key string) error {
-	v.Delta[owner+"~"+key] = nil
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.delta[owner+"~"+key] = nil
+	v.registerIDs[owner+"~"+key] = flow.RegisterID{Owner: owner, Key: key}
 	return nil
 }
@@ -0,0 +1,184 @@
+package debugger
+
+import (
+	"fmt"
+	"github.com/onflow/flow-go/model/flow"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRemoteViewConcurrentAccess(t *testing.T) {
+	parent := NewRemoteView(func(owner string, key string) (flow.RegisterValue, error) {
+		return flow.RegisterValue("remote"), nil
+	})
+	view := parent.NewChild().(*RemoteView)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			owner := fmt.Sprintf("owner%d", g)
+			for i := 0; i < 100; i++ {
+				key := fmt.Sprintf("key%d", i%10)
+				err := view.Set(owner, key, flow.RegisterValue(key))
+				if err != nil {
+					t.Error(err)
+				}
+				_, err = view.Get(owner, "unset")
+				if err != nil {
+					t.Error(err)
+				}
+				if i%10 == 9 {
+					err = view.Delete(owner, key)
+					if err != nil {
+						t.Error(err)
+					}
+				}
+				view.RegisterUpdates()
+				view.Snapshot(owner)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	ids, values := view.RegisterUpdates()
+	if len(ids) != 80 {
+		t.Fatalf("%d registers updated, expected 80", len(ids))
+	}
+	for i, id := range ids {
+		if id.Key == "key9" && values[i] != nil {
+			t.Errorf("register %s of %s was not deleted", id.Key, id.Owner)
+		}
+	}
+	if len(view.Snapshots()) != 8 {
+		t.Errorf("%d snapshots, expected 8", len(view.Snapshots()))
+	}
+}
+
+func TestRemoteViewConcurrentMerge(t *testing.T) {
+	reader := func(owner string, key string) (flow.RegisterValue, error) {
+		return nil, nil
+	}
+	a := NewRemoteView(reader)
+	b := NewRemoteView(reader)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var wg sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			wg.Add(2)
+			go func(i int) {
+				defer wg.Done()
+				_ = a.Set("a", fmt.Sprint(i), flow.RegisterValue("a"))
+				if err := a.MergeView(b); err != nil {
+					t.Error(err)
+				}
+			}(i)
+			go func(i int) {
+				defer wg.Done()
+				_ = b.Set("b", fmt.Sprint(i), flow.RegisterValue("b"))
+				if err := b.MergeView(a); err != nil {
+					t.Error(err)
+				}
+			}(i)
+		}
+		wg.Wait()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("merging two views into each other deadlocked")
+	}
+
+	err := a.MergeView(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := a.Get("b", "99")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(value) != "b" {
+		t.Errorf("merged value is %q", value)
+	}
+}
+
+// viewValues returns the values of the registers of the owner in the view.
+func viewValues(t *testing.T, view *RemoteView, keys ...string) []string {
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value, err := view.Get("owner", key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, string(value))
+	}
+	return values
+}
+
+func TestRemoteViewSnapshotAndFork(t *testing.T) {
+	view := NewRemoteView(func(owner string, key string) (flow.RegisterValue, error) {
+		return flow.RegisterValue("remote"), nil
+	})
+	set := func(view *RemoteView, key string, value string) {
+		err := view.Set("owner", key, flow.RegisterValue(value))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	set(view, "a", "1")
+	view.Snapshot("first")
+	set(view, "a", "2")
+	set(view, "b", "2")
+
+	fork := view.Fork()
+	set(view, "a", "3")
+	set(fork, "b", "fork")
+	err := fork.Delete("owner", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		view     *RemoteView
+		expected []string
+		updates  int
+	}{
+		{name: "view", view: view, expected: []string{"3", "2", "remote"}, updates: 2},
+		{name: "fork", view: fork, expected: []string{"", "fork", "remote"}, updates: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := viewValues(t, tt.view, "a", "b", "c")
+			if fmt.Sprint(values) != fmt.Sprint(tt.expected) {
+				t.Errorf("values are %v, expected %v", values, tt.expected)
+			}
+			ids, _ := tt.view.RegisterUpdates()
+			if len(ids) != tt.updates {
+				t.Errorf("%d registers updated, expected %d", len(ids), tt.updates)
+			}
+		})
+	}
+
+	err = view.Rollback("first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := viewValues(t, view, "a", "b")
+	if fmt.Sprint(values) != fmt.Sprint([]string{"1", "remote"}) {
+		t.Errorf("values after rollback are %v", values)
+	}
+	values = viewValues(t, fork, "a", "b")
+	if fmt.Sprint(values) != fmt.Sprint([]string{"", "fork"}) {
+		t.Errorf("fork values changed by the rollback to %v", values)
+	}
+	if fork.Rollback("first") == nil {
+		t.Error("snapshots must not be forked")
+	}
+}
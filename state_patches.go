@@ -0,0 +1,161 @@
+package debugger
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/execution-debugger/registers"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/pkg/errors"
+	"os"
+)
+
+// StatePatches are changes made to the state before a transaction or script is executed,
+// e.g. to see what happens if an account had more FLOW.
+type StatePatches struct {
+	// Registers are set to the given values, the keys are mangled.
+	Registers []RegisterPatch
+	// FlowBalances set the balance of the FLOW vault of the accounts, minting or burning tokens.
+	FlowBalances []FlowBalancePatch
+	// Storage saves values at storage paths, replacing the stored values.
+	Storage []StoragePatch
+}
+
+type RegisterPatch struct {
+	Key   registers.RegisterKey
+	Value flow.RegisterValue
+}
+
+type FlowBalancePatch struct {
+	Address flow.Address
+	Balance cadence.UFix64
+}
+
+type StoragePatch struct {
+	Address flow.Address
+	Path    cadence.Path
+	// Value is a JSON-Cadence encoded value.
+	Value []byte
+}
+
+// Changes describes the patches, to be listed in the run manifest.
+func (p *StatePatches) Changes() []TransactionChange {
+	var changes []TransactionChange
+	for _, patch := range p.Registers {
+		changes = append(changes, TransactionChange{
+			Field: "register " + patch.Key.String(),
+			To:    hex.EncodeToString(patch.Value),
+		})
+	}
+	for _, patch := range p.FlowBalances {
+		changes = append(changes, TransactionChange{
+			Field: "flowBalance " + patch.Address.HexWithPrefix(),
+			To:    patch.Balance.String(),
+		})
+	}
+	for _, patch := range p.Storage {
+		changes = append(changes, TransactionChange{
+			Field: "storage " + patch.Address.HexWithPrefix() + patch.Path.String(),
+			To:    string(patch.Value),
+		})
+	}
+	return changes
+}
+
+// StatePatchesFile is the on-disk format of state patches.
+type StatePatchesFile struct {
+	Registers []struct {
+		// Owner and Key are readable, as in the register files, e.g. "1654653399040a61" and "code.FlowToken".
+		Owner string `json:"owner"`
+		Key   string `json:"key"`
+		// Value is hex encoded.
+		Value string `json:"value"`
+	} `json:"registers"`
+	FlowBalances []struct {
+		Address string `json:"address"`
+		// Balance is a UFix64, e.g. "100.0".
+		Balance string `json:"balance"`
+	} `json:"flowBalances"`
+	Storage []struct {
+		Address string `json:"address"`
+		// Path is a storage path, e.g. "/storage/counter".
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	} `json:"storage"`
+}
+
+// LoadStatePatches reads the state patches from the given file.
+func LoadStatePatches(path string) (*StatePatches, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read patches file")
+	}
+
+	var file StatePatchesFile
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed decoding patches file")
+	}
+
+	patches := &StatePatches{}
+	for _, register := range file.Registers {
+		value, err := hex.DecodeString(register.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value of register %s/%s", register.Owner, register.Key)
+		}
+		patches.Registers = append(patches.Registers, RegisterPatch{
+			Key:   registers.RegisterKey{Owner: register.Owner, Key: register.Key}.ToMangled(),
+			Value: value,
+		})
+	}
+
+	for _, balance := range file.FlowBalances {
+		address, err := ParseAddress(balance.Address)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid flow balance address")
+		}
+		amount, err := cadence.NewUFix64(balance.Balance)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid flow balance of %s", balance.Address)
+		}
+		patches.FlowBalances = append(patches.FlowBalances, FlowBalancePatch{
+			Address: address,
+			Balance: amount,
+		})
+	}
+
+	for _, storage := range file.Storage {
+		address, err := ParseAddress(storage.Address)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid storage address")
+		}
+		path, err := parseStoragePath(storage.Path)
+		if err != nil {
+			return nil, err
+		}
+		_, err = jsoncdc.Decode(nil, storage.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid JSON-Cadence value for %s", storage.Path)
+		}
+		patches.Storage = append(patches.Storage, StoragePatch{
+			Address: address,
+			Path:    path,
+			Value:   storage.Value,
+		})
+	}
+
+	return patches, nil
+}
+
+func parseStoragePath(path string) (cadence.Path, error) {
+	const prefix = "/storage/"
+	if len(path) <= len(prefix) || path[:len(prefix)] != prefix {
+		return cadence.Path{}, fmt.Errorf("%s is not a storage path", path)
+	}
+	return cadence.Path{
+		Domain:     "storage",
+		Identifier: path[len(prefix):],
+	}, nil
+}
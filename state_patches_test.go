@@ -0,0 +1,118 @@
+package debugger
+
+import (
+	"github.com/onflow/cadence"
+	"github.com/onflow/execution-debugger/registers"
+	"github.com/onflow/flow-go/model/flow"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseStoragePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected cadence.Path
+		err      bool
+	}{
+		{path: "/storage/counter", expected: cadence.Path{Domain: "storage", Identifier: "counter"}},
+		{path: "/storage/flowTokenVault", expected: cadence.Path{Domain: "storage", Identifier: "flowTokenVault"}},
+		{path: "/storage/", err: true},
+		{path: "/public/counter", err: true},
+		{path: "storage/counter", err: true},
+		{path: "", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, err := parseStoragePath(tt.path)
+			if (err != nil) != tt.err {
+				t.Fatalf("error is %v", err)
+			}
+			if path != tt.expected {
+				t.Errorf("path is %v, expected %v", path, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadStatePatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		check func(t *testing.T, patches *StatePatches)
+		err   bool
+	}{
+		{
+			name: "empty",
+			file: `{}`,
+			check: func(t *testing.T, patches *StatePatches) {
+				if len(patches.Changes()) != 0 {
+					t.Errorf("changes are %v", patches.Changes())
+				}
+			},
+		},
+		{
+			name: "all patches",
+			file: `{
+				"registers": [{"owner": "1654653399040a61", "key": "code.FlowToken", "value": "0a0b"}],
+				"flowBalances": [{"address": "0x1654653399040a61", "balance": "100.5"}],
+				"storage": [{"address": "1654653399040a61", "path": "/storage/counter", "value": {"type": "Int", "value": "1"}}]
+			}`,
+			check: func(t *testing.T, patches *StatePatches) {
+				address := flow.HexToAddress("1654653399040a61")
+				key := registers.RegisterKey{Owner: "1654653399040a61", Key: "code.FlowToken"}.ToMangled()
+				if len(patches.Registers) != 1 || patches.Registers[0].Key != key || string(patches.Registers[0].Value) != "\x0a\x0b" {
+					t.Errorf("registers are %v", patches.Registers)
+				}
+				balance, _ := cadence.NewUFix64("100.5")
+				if len(patches.FlowBalances) != 1 || patches.FlowBalances[0] != (FlowBalancePatch{Address: address, Balance: balance}) {
+					t.Errorf("flow balances are %v", patches.FlowBalances)
+				}
+				if len(patches.Storage) != 1 || patches.Storage[0].Address != address ||
+					patches.Storage[0].Path != (cadence.Path{Domain: "storage", Identifier: "counter"}) {
+					t.Errorf("storage is %v", patches.Storage)
+				}
+				if len(patches.Changes()) != 3 {
+					t.Errorf("changes are %v", patches.Changes())
+				}
+			},
+		},
+		{
+			name: "global register",
+			file: `{"registers": [{"owner": "", "key": "uuid", "value": "0a"}]}`,
+			check: func(t *testing.T, patches *StatePatches) {
+				if len(patches.Registers) != 1 || patches.Registers[0].Key != (registers.RegisterKey{Owner: "", Key: "uuid"}) {
+					t.Errorf("registers are %v, expected the global uuid register", patches.Registers)
+				}
+			},
+		},
+		{name: "invalid json", file: `{`, err: true},
+		{name: "invalid register value", file: `{"registers": [{"owner": "01", "key": "k", "value": "xyz"}]}`, err: true},
+		{name: "invalid balance address", file: `{"flowBalances": [{"address": "0xzz", "balance": "1.0"}]}`, err: true},
+		{name: "invalid balance", file: `{"flowBalances": [{"address": "0x01", "balance": "-1.0"}]}`, err: true},
+		{name: "invalid storage path", file: `{"storage": [{"address": "0x01", "path": "/public/a", "value": {"type": "Int", "value": "1"}}]}`, err: true},
+		{name: "invalid storage value", file: `{"storage": [{"address": "0x01", "path": "/storage/a", "value": {"type": "Int"}}]}`, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "patches.json")
+			err := os.WriteFile(path, []byte(tt.file), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			patches, err := LoadStatePatches(path)
+			if (err != nil) != tt.err {
+				t.Fatalf("error is %v", err)
+			}
+			if tt.check != nil {
+				tt.check(t, patches)
+			}
+		})
+	}
+
+	_, err := LoadStatePatches(filepath.Join(t.TempDir(), "missing.json"))
+	if err == nil {
+		t.Error("missing file must be an error")
+	}
+}
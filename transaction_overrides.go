@@ -0,0 +1,252 @@
+package debugger
+
+import (
+	"encoding/json"
+	"fmt"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go/model/flow"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// TransactionChange describes a change made to a transaction before it was executed.
+type TransactionChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// ChangeDescriber is implemented by resolvers that modify the transaction they resolve,
+// the changes are listed in the run manifest.
+type ChangeDescriber interface {
+	Changes() ([]TransactionChange, error)
+}
+
+var _ TransactionResolver = &OverriddenTransaction{}
+var _ ChangeDescriber = &OverriddenTransaction{}
+var _ TransactionIdentifier = &OverriddenTransaction{}
+
+// OverriddenTransaction decorates a transaction resolver and replaces parts of the resolved transaction,
+// e.g. to replay a failed transaction with a different argument.
+// Zero values mean the original value is kept.
+type OverriddenTransaction struct {
+	Resolver TransactionResolver
+
+	Script []byte
+	// Arguments are JSON-Cadence encoded values by argument index.
+	Arguments   map[int][]byte
+	GasLimit    uint64
+	Authorizers []flow.Address
+
+	// original is the body resolved by the decorated resolver, it is fetched once
+	mu       sync.Mutex
+	original *flow.TransactionBody
+}
+
+// originalBody returns the transaction of the decorated resolver, resolving it on the first call.
+func (o *OverriddenTransaction) originalBody() (*flow.TransactionBody, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.original != nil {
+		return o.original, nil
+	}
+	original, err := o.Resolver.TransactionBody()
+	if err != nil {
+		return nil, err
+	}
+	o.original = original
+	return original, nil
+}
+
+func (o *OverriddenTransaction) TransactionBody() (*flow.TransactionBody, error) {
+	original, err := o.originalBody()
+	if err != nil {
+		return nil, err
+	}
+
+	// copy so the resolver's transaction is not modified
+	txBody := *original
+	txBody.Arguments = append([][]byte{}, original.Arguments...)
+	txBody.Authorizers = append([]flow.Address{}, original.Authorizers...)
+
+	if o.Script != nil {
+		txBody.Script = o.Script
+	}
+	for i, argument := range o.Arguments {
+		if i < 0 || i >= len(txBody.Arguments) {
+			return nil, fmt.Errorf("argument override index %d out of range, transaction has %d arguments", i, len(txBody.Arguments))
+		}
+		txBody.Arguments[i] = argument
+	}
+	if o.GasLimit != 0 {
+		txBody.GasLimit = o.GasLimit
+	}
+	if o.Authorizers != nil {
+		txBody.Authorizers = o.Authorizers
+	}
+
+	return &txBody, nil
+}
+
+func (o *OverriddenTransaction) BlockHeight() (uint64, error) {
+	return o.Resolver.BlockHeight()
+}
+
+// ImpersonatedAccounts passes through the impersonated accounts of the decorated resolver.
+func (o *OverriddenTransaction) ImpersonatedAccounts() ([]flow.Address, error) {
+	if impersonator, ok := o.Resolver.(Impersonator); ok {
+		return impersonator.ImpersonatedAccounts()
+	}
+	return nil, nil
+}
+
+// TransactionID returns the ID of the original transaction, not the ID of the overridden one.
+func (o *OverriddenTransaction) TransactionID() (flow.Identifier, error) {
+	if identifier, ok := o.Resolver.(TransactionIdentifier); ok {
+		return identifier.TransactionID()
+	}
+	original, err := o.originalBody()
+	if err != nil {
+		return flow.ZeroID, err
+	}
+	return original.ID(), nil
+}
+
+// Changes lists the differences between the original and the overridden transaction.
+func (o *OverriddenTransaction) Changes() ([]TransactionChange, error) {
+	original, err := o.originalBody()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []TransactionChange
+	if describer, ok := o.Resolver.(ChangeDescriber); ok {
+		changes, err = describer.Changes()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if o.Script != nil {
+		changes = append(changes, TransactionChange{
+			Field: "script",
+			From:  string(original.Script),
+			To:    string(o.Script),
+		})
+	}
+
+	indexes := make([]int, 0, len(o.Arguments))
+	for i := range o.Arguments {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	for _, i := range indexes {
+		from := ""
+		if i >= 0 && i < len(original.Arguments) {
+			from = strings.TrimSpace(string(original.Arguments[i]))
+		}
+		changes = append(changes, TransactionChange{
+			Field: "arguments[" + strconv.Itoa(i) + "]",
+			From:  from,
+			To:    strings.TrimSpace(string(o.Arguments[i])),
+		})
+	}
+
+	if o.GasLimit != 0 {
+		changes = append(changes, TransactionChange{
+			Field: "gasLimit",
+			From:  strconv.FormatUint(original.GasLimit, 10),
+			To:    strconv.FormatUint(o.GasLimit, 10),
+		})
+	}
+
+	if o.Authorizers != nil {
+		changes = append(changes, TransactionChange{
+			Field: "authorizers",
+			From:  addressList(original.Authorizers),
+			To:    addressList(o.Authorizers),
+		})
+	}
+
+	return changes, nil
+}
+
+func addressList(addresses []flow.Address) string {
+	hexAddresses := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		hexAddresses = append(hexAddresses, address.HexWithPrefix())
+	}
+	return strings.Join(hexAddresses, ",")
+}
+
+// TransactionOverridesFile is the on-disk format of transaction overrides.
+type TransactionOverridesFile struct {
+	// ScriptFile is the path to the replacement .cdc code, relative to the overrides file.
+	ScriptFile string `json:"scriptFile"`
+	// Arguments are JSON-Cadence encoded values by argument index.
+	Arguments   map[string]json.RawMessage `json:"arguments"`
+	GasLimit    uint64                     `json:"gasLimit"`
+	Authorizers []string                   `json:"authorizers"`
+}
+
+// LoadTransactionOverrides decorates the resolver with the overrides from the given file.
+func LoadTransactionOverrides(path string, resolver TransactionResolver) (*OverriddenTransaction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read overrides file")
+	}
+
+	var file TransactionOverridesFile
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed decoding overrides file")
+	}
+
+	overridden := &OverriddenTransaction{
+		Resolver:  resolver,
+		Arguments: make(map[int][]byte, len(file.Arguments)),
+		GasLimit:  file.GasLimit,
+	}
+
+	if file.ScriptFile != "" {
+		scriptFile := file.ScriptFile
+		if !filepath.IsAbs(scriptFile) {
+			scriptFile = filepath.Join(filepath.Dir(path), scriptFile)
+		}
+		overridden.Script, err = os.ReadFile(scriptFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read script file")
+		}
+	}
+
+	for index, argument := range file.Arguments {
+		i, err := strconv.Atoi(index)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid argument index %s", index)
+		}
+		_, err = jsoncdc.Decode(nil, argument)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid JSON-Cadence argument %d", i)
+		}
+		overridden.Arguments[i] = argument
+	}
+
+	if file.Authorizers != nil {
+		overridden.Authorizers = make([]flow.Address, 0, len(file.Authorizers))
+		for _, authorizer := range file.Authorizers {
+			address, err := ParseAddress(authorizer)
+			if err != nil {
+				return nil, errors.Wrap(err, "invalid authorizer")
+			}
+			overridden.Authorizers = append(overridden.Authorizers, address)
+		}
+	}
+
+	return overridden, nil
+}
@@ -0,0 +1,71 @@
+package debugger
+
+import (
+	"github.com/onflow/flow-go/model/flow"
+	"testing"
+)
+
+// countingResolver counts how often the transaction is resolved.
+type countingResolver struct {
+	CustomTransaction
+	resolved int
+}
+
+func (c *countingResolver) TransactionBody() (*flow.TransactionBody, error) {
+	c.resolved++
+	return c.CustomTransaction.TransactionBody()
+}
+
+func TestOverriddenTransaction(t *testing.T) {
+	original := flow.NewTransactionBody().
+		SetScript([]byte("transaction {}")).
+		SetGasLimit(100)
+	resolver := &countingResolver{CustomTransaction: CustomTransaction{Tx: original}}
+	overridden := &OverriddenTransaction{
+		Resolver: resolver,
+		GasLimit: 200,
+	}
+
+	txBody, err := overridden.TransactionBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txBody.GasLimit != 200 || original.GasLimit != 100 {
+		t.Errorf("gas limit is %d, original gas limit is %d", txBody.GasLimit, original.GasLimit)
+	}
+
+	changes, err := overridden.Changes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0] != (TransactionChange{Field: "gasLimit", From: "100", To: "200"}) {
+		t.Errorf("changes are %v", changes)
+	}
+
+	txID, err := overridden.TransactionID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txID != original.ID() {
+		t.Errorf("transaction ID is %s, expected the original ID %s", txID, original.ID())
+	}
+	if resolver.resolved != 1 {
+		t.Errorf("original transaction resolved %d times", resolver.resolved)
+	}
+}
+
+func TestOverriddenTransactionID(t *testing.T) {
+	networkID := flow.Identifier{1, 2, 3}
+	overridden := &OverriddenTransaction{
+		Resolver: &NetworkTransactions{ID: networkID},
+		GasLimit: 200,
+	}
+
+	txID, err := overridden.TransactionID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txID != networkID {
+		t.Errorf("transaction ID is %s, expected the network ID %s", txID, networkID)
+	}
+}
@@ -13,7 +13,20 @@ type TransactionResolver interface {
 	BlockHeight() (uint64, error)
 }
 
+// Impersonator is implemented by resolvers of simulated transactions,
+// whose proposer, payer or authorizers can act without signing the transaction.
+type Impersonator interface {
+	ImpersonatedAccounts() ([]flow.Address, error)
+}
+
+// TransactionIdentifier is implemented by resolvers that know the ID the transaction has on the network.
+// The ID of the resolved body differs from it if the resolver changed the transaction.
+type TransactionIdentifier interface {
+	TransactionID() (flow.Identifier, error)
+}
+
 var _ TransactionResolver = &NetworkTransactions{}
+var _ TransactionIdentifier = &NetworkTransactions{}
 
 // NetworkTransactions implements transaction resolver that fetches existing transaction
 // from the Flow network using the archive node client.
@@ -43,6 +56,10 @@ func (n *NetworkTransactions) TransactionBody() (*flow.TransactionBody, error) {
 	return &txBody, nil
 }
 
+func (n *NetworkTransactions) TransactionID() (flow.Identifier, error) {
+	return n.ID, nil
+}
+
 func (n *NetworkTransactions) BlockHeight() (uint64, error) {
 	response, err := n.Client.GetHeightForTransaction(
 		context.Background(),
@@ -57,6 +74,28 @@ func (n *NetworkTransactions) BlockHeight() (uint64, error) {
 	return response.Height, nil
 }
 
+// TransactionResult returns the sealed result of the transaction.
+func (n *NetworkTransactions) TransactionResult() (*flow.TransactionResult, error) {
+	response, err := n.Client.GetResult(
+		context.Background(),
+		&dps.GetResultRequest{
+			TransactionID: n.ID[:],
+		},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get transaction result from the network")
+	}
+
+	codec := zbor.NewCodec()
+	var result flow.TransactionResult
+	err = codec.Unmarshal(response.Data, &result)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed decoding transaction result")
+	}
+
+	return &result, nil
+}
+
 var _ TransactionResolver = &CustomTransaction{}
 
 // CustomTransaction implements transaction resolver that returns a transaction that was